@@ -0,0 +1,53 @@
+package go_walk
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+)
+
+// AccessReport describes how complete a scan was. Scans run without
+// administrative privileges commonly cannot read parts of the system.
+type AccessReport struct {
+	Privileged        bool     `json:"privileged" yaml:"privileged"`                                     // Whether the scan ran with administrative privileges.
+	Inaccessible      int      `json:"inaccessible" yaml:"inaccessible"`                                 // Number of paths that could not be read.
+	PolicyDenied      int      `json:"policy_denied" yaml:"policy_denied"`                               // Number of those denied by a mandatory access control policy, see ClassPolicy.
+	InaccessiblePaths []string `json:"inaccessible_paths,omitempty" yaml:"inaccessible_paths,omitempty"` // Outermost inaccessible paths, sorted.
+}
+
+// CheckAccess builds an AccessReport from the error returned by a scan, such
+// as ListDirStat or ScanAllMounts.
+func CheckAccess(err error) AccessReport {
+	report := AccessReport{Privileged: isPrivileged()}
+
+	var errs ErrorList
+	if err != nil {
+		errs.add(err)
+	}
+
+	var paths []string
+	for _, e := range errs {
+		path, ok := errorPath(e)
+		if !errors.Is(e, fs.ErrPermission) || !ok {
+			continue
+		}
+		report.Inaccessible++
+		if Classify(e) == ClassPolicy {
+			report.PolicyDenied++
+		}
+		paths = append(paths, path)
+	}
+
+	sort.Slice(paths, func(i, j int) bool {
+		return pathLess(paths[i], paths[j])
+	})
+	for _, path := range paths {
+		n := len(report.InaccessiblePaths)
+		if n > 0 && isWithin(path, report.InaccessiblePaths[n-1]) {
+			continue
+		}
+		report.InaccessiblePaths = append(report.InaccessiblePaths, path)
+	}
+
+	return report
+}
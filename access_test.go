@@ -0,0 +1,121 @@
+package go_walk
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAccess(t *testing.T) {
+	err := ErrorList{
+		&fs.PathError{Op: "open", Path: "/root/private", Err: fs.ErrPermission},
+		&fs.PathError{Op: "open", Path: "/root/private/nested", Err: fs.ErrPermission},
+		&fs.PathError{Op: "open", Path: "/etc/ssl/private", Err: fs.ErrPermission},
+		&fs.PathError{Op: "lstat", Path: "/tmp/gone", Err: fs.ErrNotExist},
+		errors.New("unrelated"),
+	}
+
+	report := CheckAccess(err)
+	assert.Equal(t, 3, report.Inaccessible)
+	assert.Equal(t, []string{"/etc/ssl/private", "/root/private"}, report.InaccessiblePaths)
+
+	report = CheckAccess(nil)
+	assert.Zero(t, report.Inaccessible)
+	assert.Empty(t, report.InaccessiblePaths)
+}
+
+func TestListDirStatInaccessible(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for the superuser")
+	}
+
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-list-dir-stat-inaccessible-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	readable := filepath.Join(tmpDir, "readable", "node_modules")
+	private := filepath.Join(tmpDir, "private")
+
+	err = os.MkdirAll(readable, 0755)
+	assert.NoError(t, err)
+	err = os.MkdirAll(filepath.Join(private, "node_modules"), 0755)
+	assert.NoError(t, err)
+	err = os.Chmod(private, 0)
+	assert.NoError(t, err)
+	defer os.Chmod(private, 0755)
+
+	// The rest of the tree is still scanned
+	directories, err := ListDirStat(tmpDir, WithKeywords("node_modules"))
+	assert.Error(t, err)
+	assert.Len(t, directories, 1)
+
+	report := CheckAccess(err)
+	assert.False(t, report.Privileged)
+	assert.Equal(t, []string{private}, report.InaccessiblePaths)
+}
+
+func TestDirStatWithErrorPolicy(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for the superuser")
+	}
+
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-dir-stat-with-error-policy-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	private := filepath.Join(tmpDir, "private")
+	for _, dir := range []string{"src", "private"} {
+		err = os.MkdirAll(filepath.Join(tmpDir, dir), 0755)
+		assert.NoError(t, err)
+		err = os.WriteFile(filepath.Join(tmpDir, dir, "file"), []byte("test"), 0644)
+		assert.NoError(t, err)
+	}
+	err = os.Chmod(private, 0)
+	assert.NoError(t, err)
+	defer os.Chmod(private, 0755)
+
+	// What could be read is still measured
+	dir, err := DirStat(tmpDir)
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	assert.Equal(t, int64(4), dir.Size)
+	assert.True(t, dir.Incomplete)
+	assert.Equal(t, 1, dir.SkippedEntries)
+
+	dir, err = DirStat(filepath.Join(tmpDir, "src"))
+	assert.NoError(t, err)
+	assert.False(t, dir.Incomplete)
+
+	dir, err = DirStat(tmpDir, WithErrorPolicy(Ignore))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), dir.Size)
+
+	dir, err = DirStat(tmpDir, WithErrorPolicy(FailFast))
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	assert.Zero(t, dir.Size)
+
+	directories, err := ListDirStat(tmpDir, WithErrorPolicy(FailFast))
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	assert.Len(t, err, 1)
+	for _, directory := range directories {
+		assert.NotEqual(t, tmpDir, directory.Path)
+	}
+
+	directories, err = ListDirStat(tmpDir)
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	assert.Len(t, directories, 3)
+	for _, directory := range directories {
+		assert.Equal(t, directory.Path != filepath.Join(tmpDir, "src"), directory.Incomplete, directory.Path)
+	}
+}
@@ -0,0 +1,80 @@
+package go_walk
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DirectoryActivity describes the files recently modified directly in a
+// directory.
+type DirectoryActivity struct {
+	Path          string    `json:"path" yaml:"path"`                     // Path of the directory.
+	ModifiedSize  int64     `json:"modified_size" yaml:"modified_size"`   // Combined size of the recently modified files in bytes.
+	ModifiedFiles int       `json:"modified_files" yaml:"modified_files"` // Number of recently modified files.
+	LastModified  time.Time `json:"last_modified" yaml:"last_modified"`   // When the most recent of them was modified.
+}
+
+// RecentActivity returns the n directories below root holding the most bytes
+// in files modified within the given duration, most first, which answers
+// what just filled a disk. Files are attributed to the directory directly
+// containing them. An n of zero or less returns all directories with recent
+// modifications. Returns aggregated errors alongside the directories found if
+// they occur.
+func RecentActivity(root string, within time.Duration, n int) ([]DirectoryActivity, error) {
+	since := time.Now().Add(-within)
+	dirs := make(map[string]*DirectoryActivity)
+	var errs ErrorList
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			errs.add(err)
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			errs.add(err)
+			return nil
+		}
+		if info.ModTime().Before(since) {
+			return nil
+		}
+
+		dirPath := filepath.Dir(path)
+		dir, exists := dirs[dirPath]
+		if !exists {
+			dir = &DirectoryActivity{Path: dirPath}
+			dirs[dirPath] = dir
+		}
+		dir.ModifiedSize += info.Size()
+		dir.ModifiedFiles++
+		if info.ModTime().After(dir.LastModified) {
+			dir.LastModified = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		errs.add(err)
+	}
+
+	result := make([]DirectoryActivity, 0, len(dirs))
+	for _, dir := range dirs {
+		result = append(result, *dir)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ModifiedSize != result[j].ModifiedSize {
+			return result[i].ModifiedSize > result[j].ModifiedSize
+		}
+		return result[i].Path < result[j].Path
+	})
+	if n > 0 && len(result) > n {
+		result = result[:n]
+	}
+
+	return result, errs.err()
+}
@@ -0,0 +1,59 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecentActivity(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-recent-activity-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	logs := filepath.Join(tmpDir, "logs")
+	downloads := filepath.Join(tmpDir, "downloads")
+	archive := filepath.Join(tmpDir, "archive")
+
+	for _, dir := range []string{logs, downloads, archive} {
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(t, err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	files := map[string]struct {
+		size    int
+		modTime time.Time
+	}{
+		filepath.Join(logs, "app.log"):          {3000, now.Add(-time.Hour)},
+		filepath.Join(logs, "app.log.1"):        {500, now.Add(-48 * time.Hour)},
+		filepath.Join(downloads, "movie.mkv"):   {2000, now.Add(-2 * time.Hour)},
+		filepath.Join(downloads, "setup.exe"):   {100, now.Add(-30 * time.Minute)},
+		filepath.Join(archive, "backup.tar.gz"): {9000, now.Add(-30 * 24 * time.Hour)},
+	}
+	for path, file := range files {
+		err = os.WriteFile(path, make([]byte, file.size), 0644)
+		assert.NoError(t, err)
+		err = os.Chtimes(path, file.modTime, file.modTime)
+		assert.NoError(t, err)
+	}
+
+	dirs, err := RecentActivity(tmpDir, 24*time.Hour, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []DirectoryActivity{
+		{Path: logs, ModifiedSize: 3000, ModifiedFiles: 1, LastModified: now.Add(-time.Hour)},
+		{Path: downloads, ModifiedSize: 2100, ModifiedFiles: 2, LastModified: now.Add(-30 * time.Minute)},
+	}, dirs)
+
+	dirs, err = RecentActivity(tmpDir, 24*time.Hour, 1)
+	assert.NoError(t, err)
+	assert.Len(t, dirs, 1)
+	assert.Equal(t, logs, dirs[0].Path)
+}
@@ -0,0 +1,25 @@
+package go_walk
+
+import "time"
+
+// AgeBreakdown splits sizes by how long ago files were last modified.
+type AgeBreakdown struct {
+	LastDay   int64 `json:"last_day" yaml:"last_day"`     // Bytes modified within the last day.
+	LastWeek  int64 `json:"last_week" yaml:"last_week"`   // Bytes modified one to seven days ago.
+	LastMonth int64 `json:"last_month" yaml:"last_month"` // Bytes modified seven to thirty days ago.
+	Older     int64 `json:"older" yaml:"older"`           // Bytes modified more than thirty days ago.
+}
+
+// add adds size bytes last modified age ago.
+func (a *AgeBreakdown) add(size int64, age time.Duration) {
+	switch {
+	case age < day:
+		a.LastDay += size
+	case age < 7*day:
+		a.LastWeek += size
+	case age < 30*day:
+		a.LastMonth += size
+	default:
+		a.Older += size
+	}
+}
@@ -0,0 +1,38 @@
+package go_walk
+
+import "sync"
+
+var (
+	aliasesMu sync.RWMutex
+	// aliases maps a keyword alias to the keywords it stands for.
+	aliases = map[string][]string{
+		"js-caches": {"node_modules", ".next", ".turbo", "dist"},
+		"py-caches": {"__pycache__", ".pytest_cache", ".mypy_cache", ".ruff_cache"},
+	}
+)
+
+// RegisterAlias makes the keyword alias stand for keywords wherever keywords
+// are accepted, such as in WithKeywords, replacing any previous definition.
+// Built in are "js-caches" and "py-caches". It is safe for concurrent use.
+func RegisterAlias(alias string, keywords ...string) {
+	aliasesMu.Lock()
+	defer aliasesMu.Unlock()
+	aliases[alias] = append([]string(nil), keywords...)
+}
+
+// expandAliases returns keywords with every alias replaced by the keywords it
+// stands for.
+func expandAliases(keywords []string) []string {
+	aliasesMu.RLock()
+	defer aliasesMu.RUnlock()
+
+	expanded := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		if names, exists := aliases[keyword]; exists {
+			expanded = append(expanded, names...)
+			continue
+		}
+		expanded = append(expanded, keyword)
+	}
+	return expanded
+}
@@ -0,0 +1,52 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterAlias(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-register-alias-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	nodeModules := filepath.Join(tmpDir, "web", "node_modules")
+	next := filepath.Join(tmpDir, "web", ".next")
+	target := filepath.Join(tmpDir, "cli", "target")
+	vendor := filepath.Join(tmpDir, "cli", "vendor")
+
+	for _, dir := range []string{nodeModules, next, target, vendor} {
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(t, err)
+	}
+
+	paths := func(directories []DirectoryInfo) []string {
+		var result []string
+		for _, dir := range directories {
+			result = append(result, dir.Path)
+		}
+		return result
+	}
+
+	directories, err := ListDirStat(tmpDir, WithKeywords("js-caches"))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{nodeModules, next}, paths(directories))
+
+	RegisterAlias("test-build-output", "target", "vend*")
+	defer func() {
+		aliasesMu.Lock()
+		delete(aliases, "test-build-output")
+		aliasesMu.Unlock()
+	}()
+
+	directories, err = ListDirStat(tmpDir, WithKeywords("test-build-output"), WithMatchMode(MatchGlob))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{target, vendor}, paths(directories))
+}
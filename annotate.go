@@ -0,0 +1,69 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Annotator enriches the results of a scan, for example with tags, risk
+// scores or ownership, by adding annotations with DirectoryInfo.Annotate.
+// Scans measure directories concurrently, so an Annotator must be safe for
+// concurrent use.
+type Annotator interface {
+	Annotate(dir *DirectoryInfo)
+}
+
+// AnnotatorFunc adapts an ordinary function to the Annotator interface.
+type AnnotatorFunc func(dir *DirectoryInfo)
+
+// Annotate calls f(dir).
+func (f AnnotatorFunc) Annotate(dir *DirectoryInfo) {
+	f(dir)
+}
+
+// PresetAnnotator returns an Annotator that sets the "preset" annotation of
+// directories whose name belongs to one or more presets, such as
+// "node_modules", to the comma separated names of those presets.
+func PresetAnnotator() Annotator {
+	return AnnotatorFunc(func(dir *DirectoryInfo) {
+		if matched := presetsMatching(filepath.Base(dir.Path)); len(matched) > 0 {
+			dir.Annotate("preset", strings.Join(matched, ","))
+		}
+	})
+}
+
+// GitAnnotator returns an Annotator that sets the "git_repository"
+// annotation of directories inside a Git working tree to the root of that
+// working tree. Lookups are cached per directory for the lifetime of the
+// Annotator.
+func GitAnnotator() Annotator {
+	var roots sync.Map
+
+	var repositoryOf func(path string) string
+	repositoryOf = func(path string) string {
+		if root, ok := roots.Load(path); ok {
+			return root.(string)
+		}
+
+		root := ""
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+			root = path
+		} else if parent := filepath.Dir(path); parent != path {
+			root = repositoryOf(parent)
+		}
+		roots.Store(path, root)
+		return root
+	}
+
+	return AnnotatorFunc(func(dir *DirectoryInfo) {
+		absPath, err := filepath.Abs(dir.Path)
+		if err != nil {
+			return
+		}
+		if root := repositoryOf(absPath); root != "" {
+			dir.Annotate("git_repository", root)
+		}
+	})
+}
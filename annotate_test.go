@@ -0,0 +1,52 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithAnnotators(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-with-annotators-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	repo := filepath.Join(tmpDir, "repo")
+	repoBuild := filepath.Join(repo, "app", "build")
+	loose := filepath.Join(tmpDir, "loose", "node_modules")
+
+	for _, dir := range []string{filepath.Join(repo, ".git"), repoBuild, loose} {
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(t, err)
+	}
+
+	size := AnnotatorFunc(func(dir *DirectoryInfo) {
+		if dir.NumberOfFiles == 0 {
+			dir.Annotate("empty", "true")
+		}
+	})
+
+	directories, err := ListDirStat(tmpDir,
+		WithKeywords("build", "node_modules"),
+		WithAnnotators(PresetAnnotator(), GitAnnotator(), size),
+	)
+	assert.NoError(t, err)
+
+	annotations := make(map[string]map[string]string)
+	for _, dir := range directories {
+		annotations[dir.Path] = dir.Annotations
+	}
+
+	absRepo, err := filepath.Abs(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		repoBuild: {"preset": "gradle", "git_repository": absRepo, "empty": "true"},
+		loose:     {"preset": "node", "empty": "true"},
+	}, annotations)
+}
@@ -0,0 +1,106 @@
+package go_walk
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// anonymizedNameLength is the number of hex digits kept from the hash of
+// every anonymized path component.
+const anonymizedNameLength = 12
+
+// Anonymize returns a copy of directories with every path component replaced
+// by a keyed hash of it, so results can be shared without revealing file
+// names. Equal components map to equal hashes, which preserves the structure
+// of the tree, and sizes, counts and times are kept as they are. The paths of
+// LargestFile and SymlinkCycles are hashed the same way, as are annotations
+// holding rooted paths, such as "git_repository". The key prevents the hashes
+// of common names from being looked up; keep it secret and reuse it to make
+// several exports comparable.
+func Anonymize(directories []DirectoryInfo, key []byte) []DirectoryInfo {
+	a := newAnonymizer(key)
+	result := cloneDirectories(directories)
+	for i := range result {
+		a.directory(&result[i])
+	}
+	return result
+}
+
+// Anonymize returns a copy of the snapshot with its host and every path
+// component replaced by a keyed hash, like Anonymize.
+func (s *Snapshot) Anonymize(key []byte) *Snapshot {
+	a := newAnonymizer(key)
+	anonymized := &Snapshot{
+		Host:        a.name(s.Host),
+		Root:        a.path(s.Root),
+		CreatedAt:   s.CreatedAt,
+		Directories: cloneDirectories(s.Directories),
+	}
+	for i := range anonymized.Directories {
+		a.directory(&anonymized.Directories[i])
+	}
+	sort.Slice(anonymized.Directories, func(i, j int) bool {
+		return pathLess(anonymized.Directories[i].Path, anonymized.Directories[j].Path)
+	})
+	return anonymized
+}
+
+// anonymizer hashes path components, remembering the ones it has seen.
+type anonymizer struct {
+	key   []byte
+	names map[string]string
+}
+
+// newAnonymizer returns an anonymizer using key.
+func newAnonymizer(key []byte) *anonymizer {
+	return &anonymizer{key: key, names: make(map[string]string)}
+}
+
+// directory anonymizes the paths of dir, which must not share memory with the
+// directory it was copied from.
+func (a *anonymizer) directory(dir *DirectoryInfo) {
+	dir.Path = a.path(dir.Path)
+	if dir.LargestFile != nil {
+		dir.LargestFile.Path = a.path(dir.LargestFile.Path)
+	}
+	for i := range dir.SymlinkCycles {
+		dir.SymlinkCycles[i].Link = a.path(dir.SymlinkCycles[i].Link)
+		dir.SymlinkCycles[i].Target = a.path(dir.SymlinkCycles[i].Target)
+	}
+	for key, value := range dir.Annotations {
+		if filepath.IsAbs(value) || strings.HasPrefix(value, string(filepath.Separator)) {
+			dir.Annotations[key] = a.path(value)
+		}
+	}
+}
+
+// path anonymizes every component of path, keeping its volume name and
+// separators.
+func (a *anonymizer) path(path string) string {
+	volume := filepath.VolumeName(path)
+	components := strings.Split(path[len(volume):], string(filepath.Separator))
+	for i, component := range components {
+		components[i] = a.name(component)
+	}
+	return volume + strings.Join(components, string(filepath.Separator))
+}
+
+// name anonymizes a single name. The empty name is kept as it is.
+func (a *anonymizer) name(name string) string {
+	if name == "" {
+		return ""
+	}
+	if hashed, exists := a.names[name]; exists {
+		return hashed
+	}
+
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(name))
+	hashed := hex.EncodeToString(mac.Sum(nil))[:anonymizedNameLength]
+	a.names[name] = hashed
+	return hashed
+}
@@ -0,0 +1,66 @@
+package go_walk
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymize(t *testing.T) {
+	key := []byte("secret")
+	directories := []DirectoryInfo{
+		{
+			Path:          filepath.FromSlash("/home/alice/project/node_modules"),
+			Size:          12,
+			NumberOfFiles: 1,
+			LargestFile:   &FileInfo{Path: filepath.FromSlash("/home/alice/project/node_modules/secret.js"), Size: 12, Extension: ".js"},
+			SymlinkCycles: []SymlinkCycle{{Link: filepath.FromSlash("/home/alice/project/node_modules/loop"), Target: filepath.FromSlash("/home/alice/project")}},
+			Annotations:   map[string]string{"git_repository": filepath.FromSlash("/home/alice/project"), "preset": "npm"},
+		},
+		{Path: filepath.FromSlash("/home/alice/other/node_modules"), Size: 4},
+	}
+
+	anonymized := Anonymize(directories, key)
+	assert.Len(t, anonymized, 2)
+
+	// No path component of the original survives anywhere in the result
+	encoded, err := json.Marshal(anonymized)
+	assert.NoError(t, err)
+	for _, component := range []string{"home", "alice", "project", "other", "node_modules", "secret.js", "loop"} {
+		assert.NotContains(t, string(encoded), component)
+	}
+	assert.Equal(t, anonymized[0].Path, filepath.Dir(anonymized[0].LargestFile.Path))
+	assert.Equal(t, ".js", anonymized[0].LargestFile.Extension)
+	assert.Equal(t, anonymized[0].Path, filepath.Dir(anonymized[0].SymlinkCycles[0].Link))
+	assert.Equal(t, filepath.Dir(anonymized[0].Path), anonymized[0].SymlinkCycles[0].Target)
+	assert.Equal(t, filepath.Dir(anonymized[0].Path), anonymized[0].Annotations["git_repository"])
+	assert.Equal(t, "npm", anonymized[0].Annotations["preset"])
+
+	for i, dir := range anonymized {
+		assert.Equal(t, directories[i].Size, dir.Size)
+		assert.Equal(t, directories[i].NumberOfFiles, dir.NumberOfFiles)
+		assert.Equal(t, strings.Count(directories[i].Path, string(filepath.Separator)), strings.Count(dir.Path, string(filepath.Separator)))
+	}
+
+	// The structure is preserved and the result is stable for the same key
+	first := strings.Split(anonymized[0].Path, string(filepath.Separator))
+	second := strings.Split(anonymized[1].Path, string(filepath.Separator))
+	assert.Equal(t, first[1:3], second[1:3])
+	assert.Equal(t, first[4], second[4])
+	assert.NotEqual(t, first[3], second[3])
+	assert.Equal(t, anonymized, Anonymize(directories, key))
+	assert.NotEqual(t, anonymized, Anonymize(directories, []byte("other")))
+
+	// The original is left untouched
+	assert.Equal(t, filepath.FromSlash("/home/alice/project/node_modules"), directories[0].Path)
+	assert.Equal(t, filepath.FromSlash("/home/alice/project/node_modules/secret.js"), directories[0].LargestFile.Path)
+	assert.Equal(t, filepath.FromSlash("/home/alice/project"), directories[0].SymlinkCycles[0].Target)
+	assert.Equal(t, filepath.FromSlash("/home/alice/project"), directories[0].Annotations["git_repository"])
+
+	snapshot := (&Snapshot{Host: "laptop", Root: filepath.FromSlash("/home/alice"), Directories: directories}).Anonymize(key)
+	assert.NotEqual(t, "laptop", snapshot.Host)
+	assert.True(t, isWithin(snapshot.Directories[0].Path, snapshot.Root))
+}
@@ -0,0 +1,163 @@
+package go_walk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// DefaultLargeArtifactSize is the size from which FindArtifacts reports a
+// binary file as a large artifact, unless told otherwise.
+const DefaultLargeArtifactSize = 10 << 20
+
+// artifactDirs maps the names of directories that only hold build output or
+// installed dependencies to the kind of artifact they are.
+var artifactDirs = map[string]string{
+	"node_modules":  "dependencies",
+	"__pycache__":   "bytecode cache",
+	".pytest_cache": "test cache",
+	".mypy_cache":   "type checker cache",
+}
+
+// artifactFiles maps file name patterns, in the syntax of filepath.Match, to
+// the kind of artifact they are.
+var artifactFiles = map[string]string{
+	".DS_Store":     "Finder metadata",
+	"Thumbs.db":     "thumbnail cache",
+	"*.o":           "object file",
+	"*.obj":         "object file",
+	"*.a":           "static library",
+	"*.so":          "shared library",
+	"*.dylib":       "shared library",
+	"*.dll":         "shared library",
+	"*.exe":         "executable",
+	"*.pyc":         "Python bytecode",
+	"*.class":       "Java bytecode",
+	"*.swp":         "editor swap file",
+	"npm-debug.log": "debug log",
+}
+
+// Artifact is a build artifact or other generated file found in a working
+// tree.
+type Artifact struct {
+	Path  string `json:"path" yaml:"path"`     // Path of the file or directory.
+	Kind  string `json:"kind" yaml:"kind"`     // What kind of artifact it is, e.g. "object file".
+	Size  int64  `json:"size" yaml:"size"`     // Size in bytes, recursively for directories.
+	IsDir bool   `json:"is_dir" yaml:"is_dir"` // Whether the artifact is a directory.
+}
+
+// FindArtifacts reports the build artifacts and other generated files in the
+// working tree rooted at root: dependency and cache directories such as
+// node_modules, generated files such as .DS_Store or *.o, and binary files of
+// at least largeFile bytes. A largeFile of zero or less uses
+// DefaultLargeArtifactSize. The .git directory is not searched. Returns
+// aggregated errors alongside the artifacts found if they occur.
+func FindArtifacts(root string, largeFile int64) ([]Artifact, error) {
+	if largeFile <= 0 {
+		largeFile = DefaultLargeArtifactSize
+	}
+
+	var artifacts []Artifact
+	var errs ErrorList
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			errs.add(err)
+			return nil
+		}
+
+		if entry.IsDir() {
+			if path == root {
+				return nil
+			}
+			if entry.Name() == ".git" {
+				return fs.SkipDir
+			}
+			if kind, exists := artifactDirs[entry.Name()]; exists {
+				dirStat, err := calculateDirStats(context.Background(), path, newOptions())
+				if err != nil {
+					errs.add(err)
+				}
+				artifacts = append(artifacts, Artifact{Path: path, Kind: kind, Size: dirStat.Size, IsDir: true})
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			errs.add(err)
+			return nil
+		}
+
+		for pattern, kind := range artifactFiles {
+			if ok, _ := filepath.Match(pattern, entry.Name()); ok {
+				artifacts = append(artifacts, Artifact{Path: path, Kind: kind, Size: info.Size()})
+				return nil
+			}
+		}
+
+		if info.Mode().IsRegular() && info.Size() >= largeFile && isBinaryFile(path) {
+			artifacts = append(artifacts, Artifact{Path: path, Kind: "large binary", Size: info.Size()})
+		}
+		return nil
+	})
+	if err != nil {
+		errs.add(err)
+	}
+
+	return artifacts, errs.err()
+}
+
+// isBinaryFile reports whether the file at path looks binary, that is its
+// first bytes contain a NUL byte.
+func isBinaryFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	head := make([]byte, 8000)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return false
+	}
+	return bytes.IndexByte(head[:n], 0) >= 0
+}
+
+// NoArtifacts returns a Rule, for use with Check, that is broken by every
+// artifact FindArtifacts reports with a largeFile threshold of largeFile.
+func NoArtifacts(largeFile int64) Rule {
+	return &noArtifactsRule{largeFile: largeFile}
+}
+
+// noArtifactsRule is the Rule returned by NoArtifacts.
+type noArtifactsRule struct {
+	largeFile int64
+}
+
+// Name returns a short description of the rule.
+func (r *noArtifactsRule) Name() string {
+	return "no build artifacts"
+}
+
+// Violations returns the artifacts below root.
+func (r *noArtifactsRule) Violations(root string) ([]Violation, error) {
+	artifacts, err := FindArtifacts(root, r.largeFile)
+
+	var violations []Violation
+	for _, artifact := range artifacts {
+		violations = append(violations, Violation{
+			Rule:   r.Name(),
+			Path:   artifact.Path,
+			Size:   artifact.Size,
+			Reason: fmt.Sprintf("%s should not be in the working tree", artifact.Kind),
+		})
+	}
+	return violations, err
+}
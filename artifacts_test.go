@@ -0,0 +1,56 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindArtifacts(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-find-artifacts-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	nodeModules := filepath.Join(tmpDir, "web", "node_modules")
+	src := filepath.Join(tmpDir, "src")
+	gitDir := filepath.Join(tmpDir, ".git")
+
+	for _, dir := range []string{filepath.Join(nodeModules, "left-pad"), src, gitDir} {
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(t, err)
+	}
+
+	files := map[string][]byte{
+		filepath.Join(nodeModules, "left-pad", "index.js"): []byte("test content"),
+		filepath.Join(src, "main.c"):                       []byte("int main() {}"),
+		filepath.Join(src, "main.o"):                       []byte("\x7fELF"),
+		filepath.Join(tmpDir, ".DS_Store"):                 []byte("test"),
+		filepath.Join(tmpDir, "dump.bin"):                  append(make([]byte, 2048), 1),
+		filepath.Join(tmpDir, "notes.txt"):                 []byte("large but text, large but text"),
+		filepath.Join(gitDir, "index.o"):                   []byte("ignored"),
+	}
+	for path, content := range files {
+		err = os.WriteFile(path, content, 0644)
+		assert.NoError(t, err)
+	}
+
+	artifacts, err := FindArtifacts(tmpDir, 16)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []Artifact{
+		{Path: nodeModules, Kind: "dependencies", Size: 12, IsDir: true},
+		{Path: filepath.Join(src, "main.o"), Kind: "object file", Size: 4},
+		{Path: filepath.Join(tmpDir, ".DS_Store"), Kind: "Finder metadata", Size: 4},
+		{Path: filepath.Join(tmpDir, "dump.bin"), Kind: "large binary", Size: 2049},
+	}, artifacts)
+
+	result, err := Check(tmpDir, NoArtifacts(16))
+	assert.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Len(t, result.Violations, 4)
+}
@@ -0,0 +1,107 @@
+package go_walk
+
+import (
+	"context"
+	"io/fs"
+)
+
+// PermissionIssue is a file or directory whose mode is a likely security
+// concern.
+type PermissionIssue struct {
+	Path  string      `json:"path" yaml:"path"`   // Path of the entry.
+	Mode  fs.FileMode `json:"mode" yaml:"mode"`   // Mode of the entry.
+	Issue string      `json:"issue" yaml:"issue"` // What is unusual about the mode, e.g. "setuid".
+}
+
+// AuditPermissions walks root and reports world-writable files and
+// directories, setuid and setgid files and other unusual modes, such as
+// entries without any permissions or that grant others more than their owner.
+// World-writable directories with the sticky bit set, such as /tmp, are not
+// reported. The walk honours WithExclude, WithMaxDepth, WithOneFileSystem and
+// WithMiddleware as ListDirStat does. Symbolic links are skipped unless they
+// are followed with WithSymlinks(FollowSymlinks) or WithFollowSymlinks, in
+// which case the mode of their target is audited without descending into it.
+// Returns aggregated errors alongside the issues found according to the
+// ErrorPolicy.
+func AuditPermissions(root string, opts ...Option) ([]PermissionIssue, error) {
+	o := newOptions(opts...)
+	w, err := newScanWalker(root, o, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []PermissionIssue
+	var errs ErrorList
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	report := newReporter(o, errs.add, cancel)
+
+	audit := func(path string, entry fs.DirEntry) {
+		var info fs.FileInfo
+		var err error
+		if entry.Type()&fs.ModeSymlink != 0 {
+			if o.symlinks != FollowSymlinks && !o.followed(path) {
+				return
+			}
+			if info, err = o.stat(path); err != nil {
+				// Dangling links have no mode to audit.
+				return
+			}
+		} else if info, err = entry.Info(); err != nil {
+			report(err)
+			return
+		}
+
+		for _, issue := range permissionIssues(info.Mode()) {
+			issues = append(issues, PermissionIssue{Path: path, Mode: info.Mode(), Issue: issue})
+		}
+	}
+	w.onFile = audit
+
+	err = w.walk(ctx, report, func(path string, entry fs.DirEntry) bool {
+		audit(path, entry)
+		return true
+	})
+	if err != nil {
+		report(err)
+	}
+
+	if o.errorPolicy == FailFast && len(errs) > 0 {
+		return issues, errs[0]
+	}
+	return issues, errs.err()
+}
+
+// permissionIssues returns what is unusual about mode.
+func permissionIssues(mode fs.FileMode) []string {
+	var issues []string
+	perm := mode.Perm()
+
+	if perm&0o002 != 0 {
+		switch {
+		case !mode.IsDir():
+			issues = append(issues, "world-writable file")
+		case mode&fs.ModeSticky == 0:
+			issues = append(issues, "world-writable directory")
+		}
+	}
+	if mode&fs.ModeSetuid != 0 {
+		issues = append(issues, "setuid")
+	}
+	if mode&fs.ModeSetgid != 0 && !mode.IsDir() {
+		issues = append(issues, "setgid")
+	}
+
+	owner := perm >> 6 & 0o7
+	group := perm >> 3 & 0o7
+	other := perm & 0o7
+	switch {
+	case perm == 0:
+		issues = append(issues, "no permissions")
+	case group&^owner != 0 || other&^owner != 0:
+		issues = append(issues, "others exceed owner")
+	}
+
+	return issues
+}
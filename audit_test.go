@@ -0,0 +1,146 @@
+package go_walk
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/akshaybabloo/go-walk/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permission bits are not supported on Windows")
+	}
+
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-audit-permissions-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	shared := filepath.Join(tmpDir, "shared")
+	sticky := filepath.Join(tmpDir, "sticky")
+	for _, dir := range []string{shared, sticky} {
+		err = os.Mkdir(dir, 0755)
+		assert.NoError(t, err)
+	}
+	err = os.Chmod(shared, 0777)
+	assert.NoError(t, err)
+	err = os.Chmod(sticky, 0777|fs.ModeSticky)
+	assert.NoError(t, err)
+
+	files := map[string]fs.FileMode{
+		filepath.Join(tmpDir, "ok.txt"):    0644,
+		filepath.Join(tmpDir, "open.txt"):  0666,
+		filepath.Join(tmpDir, "tool"):      0755 | fs.ModeSetuid,
+		filepath.Join(tmpDir, "odd.txt"):   0407,
+		filepath.Join(tmpDir, "locked.db"): 0,
+	}
+	for path, mode := range files {
+		err = os.WriteFile(path, []byte("test"), 0644)
+		assert.NoError(t, err)
+		err = os.Chmod(path, mode)
+		assert.NoError(t, err)
+	}
+
+	issues, err := AuditPermissions(tmpDir)
+	assert.NoError(t, err)
+
+	found := make(map[string][]string)
+	for _, issue := range issues {
+		found[filepath.Base(issue.Path)] = append(found[filepath.Base(issue.Path)], issue.Issue)
+	}
+	assert.Equal(t, map[string][]string{
+		"shared":    {"world-writable directory"},
+		"open.txt":  {"world-writable file"},
+		"tool":      {"setuid"},
+		"odd.txt":   {"world-writable file", "others exceed owner"},
+		"locked.db": {"no permissions"},
+	}, found)
+}
+
+func TestAuditPermissionsWithOptions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permission bits are not supported on Windows")
+	}
+
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"project/open.txt":        {Mode: 0666},
+		"project/vendor/open.txt": {Mode: 0666},
+		"project/deep/a/open.txt": {Mode: 0666},
+		"project/shared.txt":      fixtures.Symlink("../outside/open.txt"),
+		"outside/open.txt":        {Mode: 0666},
+	})
+	project := filepath.Join(tmpDir, "project")
+
+	paths := func(issues []PermissionIssue) []string {
+		var result []string
+		for _, issue := range issues {
+			rel, err := filepath.Rel(project, issue.Path)
+			assert.NoError(t, err)
+			result = append(result, filepath.ToSlash(rel))
+		}
+		return result
+	}
+
+	// Excluded directories are not audited, and links are skipped
+	issues, err := AuditPermissions(project, WithExclude("vendor"))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"open.txt", "deep/a/open.txt"}, paths(issues))
+
+	issues, err = AuditPermissions(project, WithExclude("vendor"), WithMaxDepth(1))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"open.txt"}, paths(issues))
+
+	// Followed links are audited by the mode of their target
+	issues, err = AuditPermissions(project, WithExclude("vendor", "deep"), WithSymlinks(FollowSymlinks))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"open.txt", "shared.txt"}, paths(issues))
+}
+
+func TestAuditPermissionsWithErrorPolicy(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for the superuser")
+	}
+
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"open.txt": {Mode: 0666},
+		"private/": {Mode: 0200},
+	})
+
+	issues, err := AuditPermissions(tmpDir)
+	assert.ErrorIs(t, err, os.ErrPermission)
+	assert.Len(t, issues, 1)
+
+	issues, err = AuditPermissions(tmpDir, WithErrorPolicy(Ignore))
+	assert.NoError(t, err)
+	assert.Len(t, issues, 1)
+
+	// The issues found before the error are kept
+	issues, err = AuditPermissions(tmpDir, WithErrorPolicy(FailFast))
+	assert.ErrorIs(t, err, os.ErrPermission)
+	assert.Len(t, issues, 1)
+}
+
+func TestAuditPermissionsFailFastKeepsIssues(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permission bits are not supported on Windows")
+	}
+
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"open.txt":         {Mode: 0666},
+		"private/open.txt": {Mode: 0666},
+	})
+
+	issues, err := AuditPermissions(tmpDir, WithMiddleware(denying("private")), WithErrorPolicy(FailFast))
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, filepath.Join(tmpDir, "open.txt"), issues[0].Path)
+	}
+}
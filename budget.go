@@ -0,0 +1,57 @@
+package go_walk
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// ErrMemoryBudget is returned, together with the directories found so far,
+// by scans that stopped because keeping more results would have taken them
+// over the budget set by WithMaxMemory.
+var ErrMemoryBudget = errors.New("the scan ran out of its memory budget")
+
+// workerMemory is the memory a worker is assumed to need for the directory
+// entries it holds while measuring a tree.
+const workerMemory = 4 << 20
+
+// WithMaxMemory keeps the memory a scan uses for its workers and results to
+// about bytes, so that it can run inside memory-constrained agents. Workers
+// are reduced to fit, and ListDirStat and the functions built on it stop once
+// the results they hold would take more than that, returning what they have
+// with ErrMemoryBudget. The budget is best-effort, as memory use is
+// estimated, and values of zero or less mean no limit.
+func WithMaxMemory(bytes int64) Option {
+	return func(o *options) {
+		o.maxMemory = bytes
+	}
+}
+
+// WithMaxCPU limits a scan to measuring n directories at once, like
+// GOMAXPROCS, whatever WithWorkers asks for, so that it leaves the other cores
+// to the rest of the process. ScanAllMounts keeps to n across all the
+// filesystems it scans. Values of zero or less mean no limit.
+func WithMaxCPU(n int) Option {
+	return func(o *options) {
+		o.maxCPU = n
+	}
+}
+
+// memSize estimates the memory held by d, leaving out the overhead of maps.
+func (d DirectoryInfo) memSize() int64 {
+	const stringHeader = int64(unsafe.Sizeof(""))
+
+	size := int64(unsafe.Sizeof(d)) + int64(len(d.Path))
+	for key, value := range d.Annotations {
+		size += 2*stringHeader + int64(len(key)+len(value))
+	}
+	for ext := range d.ByExtension {
+		size += stringHeader + int64(len(ext)) + int64(unsafe.Sizeof(ExtStat{}))
+	}
+	for _, cycle := range d.SymlinkCycles {
+		size += 2*stringHeader + int64(len(cycle.Link)+len(cycle.Target))
+	}
+	if d.LargestFile != nil {
+		size += int64(unsafe.Sizeof(*d.LargestFile)) + int64(len(d.LargestFile.Path)+len(d.LargestFile.Extension))
+	}
+	return size
+}
@@ -0,0 +1,35 @@
+package go_walk
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/akshaybabloo/go-walk/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListDirStatWithMaxMemory(t *testing.T) {
+	tree := fixtures.Tree{}
+	for i := 0; i < 20; i++ {
+		tree[fmt.Sprintf("project%d/node_modules/index.js", i)] = fixtures.File(4)
+	}
+	tmpDir := fixtures.Build(t, tree)
+
+	directories, err := ListDirStat(tmpDir, WithKeywords("node_modules"), WithMaxMemory(GB))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 20)
+
+	// Room for about five results.
+	budget := 5*directories[0].memSize() + directories[0].memSize()/2
+	directories, err = ListDirStat(tmpDir, WithKeywords("node_modules"), WithMaxMemory(budget))
+	assert.ErrorIs(t, err, ErrMemoryBudget)
+	assert.Len(t, directories, 5)
+}
+
+func TestWorkerCountWithBudgets(t *testing.T) {
+	assert.Equal(t, defaultWorkers, newOptions().workerCount())
+	assert.Equal(t, 2, newOptions(WithWorkers(32), WithMaxCPU(2)).workerCount())
+	assert.Equal(t, 4, newOptions(WithWorkers(4), WithMaxCPU(16)).workerCount())
+	assert.Equal(t, 3, newOptions(WithWorkers(32), WithMaxMemory(3*workerMemory)).workerCount())
+	assert.Equal(t, 1, newOptions(WithMaxMemory(KB)).workerCount())
+}
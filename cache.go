@@ -0,0 +1,170 @@
+package go_walk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Cache keeps the results of completed scans in memory for a limited time, so
+// that repeating an identical scan returns immediately. A Cache is safe for
+// concurrent use.
+type Cache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// cacheEntry is a cached scan result.
+type cacheEntry struct {
+	directories []DirectoryInfo
+	computedAt  time.Time
+	expires     time.Time
+}
+
+// NewCache returns a Cache whose results expire ttl after they were
+// computed. Expired results are removed when they are looked up or another
+// result is cached.
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// ListDirStat returns the cached result of ListDirStat for dirPath and opts
+// if there is one that has not expired, and runs the scan otherwise. Each
+// result carries the time it was measured in ComputedAt, and
+// WithMaxStaleness forces results older than that to be measured again.
+// Only scans that complete without errors are cached, and scans with
+// annotators, middleware or progress are never cached.
+func (c *Cache) ListDirStat(dirPath string, opts ...Option) ([]DirectoryInfo, error) {
+	o := newOptions(opts...)
+	key, cacheable, err := cacheKey(dirPath, o)
+	if err != nil {
+		return nil, err
+	}
+	if !cacheable {
+		return ListDirStat(dirPath, opts...)
+	}
+
+	now := c.now()
+	c.mu.Lock()
+	entry, exists := c.entries[key]
+	if exists && !now.Before(entry.expires) {
+		delete(c.entries, key)
+		exists = false
+	}
+	c.mu.Unlock()
+
+	fresh := o.maxStaleness <= 0 || now.Sub(entry.computedAt) <= o.maxStaleness
+	if exists && fresh {
+		return cloneDirectories(entry.directories), nil
+	}
+
+	directories, err := ListDirStat(dirPath, opts...)
+	if err != nil {
+		return directories, err
+	}
+
+	now = c.now()
+	c.mu.Lock()
+	c.evictExpired(now)
+	c.entries[key] = cacheEntry{
+		directories: cloneDirectories(directories),
+		computedAt:  now,
+		expires:     now.Add(c.ttl),
+	}
+	c.mu.Unlock()
+
+	return directories, nil
+}
+
+// evictExpired removes the results that have expired by now. The caller must
+// hold c.mu.
+func (c *Cache) evictExpired(now time.Time) {
+	for key, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Clear removes all cached results.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]cacheEntry)
+}
+
+// cacheKey returns the key identifying a scan of dirPath according to o. The
+// order and repetition of keywords and exclude patterns do not affect the
+// key, and keywords are compared once their aliases are expanded, as the scan
+// sees them. The second return value reports whether the scan can be cached
+// at all.
+func cacheKey(dirPath string, o *options) (string, bool, error) {
+	if o.matcher != nil || o.files != nil || len(o.annotators) > 0 || len(o.middlewares) > 0 || o.progress > 0 {
+		return "", false, nil
+	}
+
+	absPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return "", false, err
+	}
+
+	hash := sha256.New()
+	for _, list := range [][]string{expandAliases(o.keywords), o.exclude, o.follow} {
+		sorted := make([]string, len(list))
+		copy(sorted, list)
+		sort.Strings(sorted)
+		for i, item := range sorted {
+			if i > 0 && item == sorted[i-1] {
+				continue
+			}
+			hash.Write([]byte(item))
+			hash.Write([]byte{0})
+		}
+		hash.Write([]byte{1})
+	}
+	fmt.Fprintf(hash, "%d %d %d %d %d %s %s %d %t %t %t %t %t %d %t %d %d %t", o.matchMode, o.maxDepth, o.maxMemory, o.minSize, o.olderThan, o.modifiedAfter.Format(time.RFC3339Nano), o.modifiedBefore.Format(time.RFC3339Nano), o.symlinks, o.oneFilesystem, o.dedupe, o.fileSizes, o.extensions, o.timing, o.errorPolicy, o.sorted, o.sortKey, o.sortOrder, o.deterministic)
+
+	return absPath + "\x00" + hex.EncodeToString(hash.Sum(nil)), true, nil
+}
+
+// cloneDirectories returns a copy of directories.
+func cloneDirectories(directories []DirectoryInfo) []DirectoryInfo {
+	if directories == nil {
+		return nil
+	}
+	clone := make([]DirectoryInfo, len(directories))
+	copy(clone, directories)
+	for i := range clone {
+		if clone[i].Annotations != nil {
+			annotations := make(map[string]string, len(clone[i].Annotations))
+			for key, value := range clone[i].Annotations {
+				annotations[key] = value
+			}
+			clone[i].Annotations = annotations
+		}
+		if clone[i].ByExtension != nil {
+			byExt := make(map[string]ExtStat, len(clone[i].ByExtension))
+			for ext, stat := range clone[i].ByExtension {
+				byExt[ext] = stat
+			}
+			clone[i].ByExtension = byExt
+		}
+		clone[i].SymlinkCycles = slices.Clone(clone[i].SymlinkCycles)
+		if clone[i].LargestFile != nil {
+			largest := *clone[i].LargestFile
+			clone[i].LargestFile = &largest
+		}
+	}
+	return clone
+}
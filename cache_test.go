@@ -0,0 +1,181 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/akshaybabloo/go-walk/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-cache-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	nodeModules1 := filepath.Join(tmpDir, "project1", "node_modules")
+	nodeModules2 := filepath.Join(tmpDir, "project2", "node_modules")
+
+	err = os.MkdirAll(nodeModules1, 0755)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	cache := NewCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	directories, err := cache.ListDirStat(tmpDir, WithKeywords("node_modules"))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 1)
+
+	err = os.MkdirAll(nodeModules2, 0755)
+	assert.NoError(t, err)
+
+	// Identical scans are served from the cache, even with repeated keywords
+	directories, err = cache.ListDirStat(tmpDir, WithKeywords("node_modules", "node_modules"))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 1)
+
+	// Other scans are not
+	directories, err = cache.ListDirStat(tmpDir, WithKeywords("node_modules", "vendor"))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 2)
+
+	// Expired results are computed again
+	now = now.Add(2 * time.Minute)
+	directories, err = cache.ListDirStat(tmpDir, WithKeywords("node_modules"))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 2)
+
+	computedAt := directories[0].ComputedAt
+	assert.False(t, computedAt.IsZero())
+
+	err = os.MkdirAll(filepath.Join(tmpDir, "project3", "node_modules"), 0755)
+	assert.NoError(t, err)
+
+	// Results older than the maximum staleness are computed again
+	now = now.Add(30 * time.Second)
+	directories, err = cache.ListDirStat(tmpDir, WithKeywords("node_modules"), WithMaxStaleness(time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 2)
+	assert.Equal(t, computedAt, directories[0].ComputedAt)
+
+	directories, err = cache.ListDirStat(tmpDir, WithKeywords("node_modules"), WithMaxStaleness(10*time.Second))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 3)
+}
+
+func TestCacheEvictsExpiredResults(t *testing.T) {
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"root/project1/node_modules/": {},
+		"root/project1/vendor/":       {},
+	})
+	root := filepath.Join(tmpDir, "root")
+
+	now := time.Now()
+	cache := NewCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	_, err := cache.ListDirStat(root, WithKeywords("node_modules"))
+	assert.NoError(t, err)
+	_, err = cache.ListDirStat(root, WithKeywords("vendor"))
+	assert.NoError(t, err)
+	assert.Len(t, cache.entries, 2)
+
+	// Looking up an expired result removes it, even if the scan then fails
+	now = now.Add(2 * time.Minute)
+	moved := filepath.Join(tmpDir, "moved")
+	assert.NoError(t, os.Rename(root, moved))
+	_, err = cache.ListDirStat(root, WithKeywords("node_modules"))
+	assert.Error(t, err)
+	assert.Len(t, cache.entries, 1)
+
+	// Caching a result removes the other expired ones
+	assert.NoError(t, os.Rename(moved, root))
+	_, err = cache.ListDirStat(root, WithKeywords("node_modules", "vendor"))
+	assert.NoError(t, err)
+	assert.Len(t, cache.entries, 1)
+}
+
+func TestCacheKeyExpandsAliases(t *testing.T) {
+	RegisterAlias("test-cache-deps", "node_modules")
+	defer func() {
+		aliasesMu.Lock()
+		delete(aliases, "test-cache-deps")
+		aliasesMu.Unlock()
+	}()
+
+	key, _, err := cacheKey(".", newOptions(WithKeywords("test-cache-deps")))
+	assert.NoError(t, err)
+	expanded, _, err := cacheKey(".", newOptions(WithKeywords("node_modules")))
+	assert.NoError(t, err)
+	assert.Equal(t, expanded, key)
+
+	// Redefining the alias changes what the scan looks for
+	RegisterAlias("test-cache-deps", "vendor")
+	redefined, _, err := cacheKey(".", newOptions(WithKeywords("test-cache-deps")))
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, redefined)
+}
+
+func TestCacheKeyCoversOptions(t *testing.T) {
+	// Fields that do not change what a cached scan returns, or that make it
+	// uncacheable. Every other field must be part of the key.
+	ignored := map[string]bool{
+		"annotators":     true,
+		"middlewares":    true,
+		"matcher":        true,
+		"files":          true,
+		"progress":       true,
+		"fsys":           true,
+		"maxStaleness":   true,
+		"debounce":       true,
+		"changeInterval": true,
+		"workers":        true,
+		"scheduling":     true,
+		"maxOpenFDs":     true,
+		"maxCPU":         true,
+		"fds":            true,
+		"remoteMounts":   true,
+	}
+
+	base, cacheable, err := cacheKey(".", newOptions())
+	assert.NoError(t, err)
+	assert.True(t, cacheable)
+
+	typ := reflect.TypeOf(options{})
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if ignored[field.Name] {
+			continue
+		}
+
+		o := newOptions()
+		value := reflect.ValueOf(o).Elem().Field(i)
+		value = reflect.NewAt(value.Type(), unsafe.Pointer(value.UnsafeAddr())).Elem()
+		switch {
+		case value.Kind() == reflect.Bool:
+			value.SetBool(true)
+		case value.CanInt():
+			value.SetInt(1)
+		case value.Type() == reflect.TypeOf([]string(nil)):
+			value.Set(reflect.ValueOf([]string{"test"}))
+		case value.Type() == reflect.TypeOf(time.Time{}):
+			value.Set(reflect.ValueOf(time.Unix(1, 0)))
+		default:
+			t.Errorf("cannot set option %s of type %s; add it to cacheKey and this test", field.Name, field.Type)
+			continue
+		}
+
+		key, _, err := cacheKey(".", o)
+		assert.NoError(t, err)
+		assert.NotEqual(t, base, key, "option %s is not part of the cache key", field.Name)
+	}
+}
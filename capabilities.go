@@ -0,0 +1,64 @@
+package go_walk
+
+import "runtime/debug"
+
+// modulePath is the import path of this module.
+const modulePath = "github.com/akshaybabloo/go-walk"
+
+// Version returns the version of this module that the running program was
+// built with, such as "v1.2.3", or "(devel)" if it is not known, as when the
+// module itself is being built or tested.
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path != modulePath {
+			continue
+		}
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			return dep.Replace.Version
+		}
+		return dep.Version
+	}
+	if info.Main.Path == modulePath && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+// Features describes the features of this package that a platform supports,
+// so that tools built on it can leave out what is not available. Everything
+// not listed works everywhere.
+type Features struct {
+	// InodeStats reports whether file metadata includes device, inode and
+	// block numbers, which WithOneFileSystem, WithDedupeHardlinks and the
+	// detection of symbolic link loops by identity rely on, and which makes
+	// SizeOnDisk exact rather than estimated from the block size.
+	InodeStats bool `json:"inode_stats" yaml:"inode_stats"`
+
+	Mounts          bool `json:"mounts" yaml:"mounts"`                     // ListMounts and ScanAllMounts work.
+	FilesystemStats bool `json:"filesystem_stats" yaml:"filesystem_stats"` // FilesystemBlockSize, mount capacities and filesystem usage in Summarize are available.
+	MappedSnapshots bool `json:"mapped_snapshots" yaml:"mapped_snapshots"` // OpenSnapshot maps snapshots into memory rather than reading them whole.
+	AccessControl   bool `json:"access_control" yaml:"access_control"`     // Classify recognises denials by mandatory access control, such as SELinux or AppArmor.
+	PrivilegeCheck  bool `json:"privilege_check" yaml:"privilege_check"`   // CheckAccess reports whether the process runs with administrative privileges.
+	LocalSnapshots  bool `json:"local_snapshots" yaml:"local_snapshots"`   // Mount reports list local APFS snapshots and purgeable space.
+	ShadowStorage   bool `json:"shadow_storage" yaml:"shadow_storage"`     // Mount reports include the space used by Windows shadow copies.
+}
+
+// Capabilities returns the Features supported on the platform the program was
+// built for.
+func Capabilities() Features {
+	return Features{
+		InodeStats:      inodeStats,
+		Mounts:          mountsSupported,
+		FilesystemStats: statFSSupported,
+		MappedSnapshots: mmapSupported,
+		AccessControl:   macDetection,
+		PrivilegeCheck:  privilegeDetection,
+		LocalSnapshots:  localSnapshotsSupported,
+		ShadowStorage:   shadowStorageSupported,
+	}
+}
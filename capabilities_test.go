@@ -0,0 +1,31 @@
+package go_walk
+
+import (
+	"encoding/json"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion(t *testing.T) {
+	// Tests build the module itself, whose version is not known.
+	assert.Equal(t, "(devel)", Version())
+}
+
+func TestCapabilities(t *testing.T) {
+	features := Capabilities()
+
+	_, err := ListMounts()
+	assert.Equal(t, features.Mounts, !errors.Is(err, errors.ErrUnsupported))
+	_, err = FilesystemBlockSize(".")
+	assert.Equal(t, features.FilesystemStats, !errors.Is(err, errors.ErrUnsupported))
+
+	assert.Equal(t, runtime.GOOS == "darwin", features.LocalSnapshots)
+	assert.Equal(t, runtime.GOOS == "windows", features.ShadowStorage)
+
+	data, err := json.Marshal(features)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"inode_stats":`)
+}
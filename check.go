@@ -0,0 +1,126 @@
+package go_walk
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// Rule is a condition a directory tree has to satisfy, checked by Check.
+type Rule interface {
+	// Name returns a short description of the rule, used in violations.
+	Name() string
+	// Violations returns the directories below root that break the rule.
+	Violations(root string) ([]Violation, error)
+}
+
+// Violation describes a directory that breaks a Rule.
+type Violation struct {
+	Rule   string `json:"rule" yaml:"rule"`     // Name of the rule that is broken.
+	Path   string `json:"path" yaml:"path"`     // Directory that breaks the rule.
+	Size   int64  `json:"size" yaml:"size"`     // Size of the directory in bytes.
+	Reason string `json:"reason" yaml:"reason"` // Why the directory breaks the rule.
+}
+
+// CheckResult is the outcome of Check.
+type CheckResult struct {
+	Passed     bool        `json:"passed" yaml:"passed"`                             // Whether no rule is broken.
+	Violations []Violation `json:"violations,omitempty" yaml:"violations,omitempty"` // Every violation found.
+}
+
+// Check checks the tree rooted at root against rules and reports whether it
+// passes, along with every violation. It is meant for CI pipelines, which can
+// fail the build on !Passed and publish the violations. An error means the
+// check could not be completed; the violations found so far are still
+// returned, but Passed is false.
+func Check(root string, rules ...Rule) (CheckResult, error) {
+	var result CheckResult
+	var errs ErrorList
+
+	for _, rule := range rules {
+		violations, err := rule.Violations(root)
+		if err != nil {
+			errs.add(err)
+		}
+		result.Violations = append(result.Violations, violations...)
+	}
+
+	result.Passed = len(result.Violations) == 0 && len(errs) == 0
+	return result, errs.err()
+}
+
+// MaxDirSize returns a Rule that is broken by every directory below root
+// matching m that is larger than limit bytes. A nil Matcher checks all
+// directories.
+func MaxDirSize(limit int64, m Matcher) Rule {
+	return &maxDirSizeRule{limit: limit, matcher: m}
+}
+
+// maxDirSizeRule is the Rule returned by MaxDirSize.
+type maxDirSizeRule struct {
+	limit   int64
+	matcher Matcher
+}
+
+// Name returns a short description of the rule.
+func (r *maxDirSizeRule) Name() string {
+	return fmt.Sprintf("max directory size %d bytes", r.limit)
+}
+
+// Violations returns the directories below root that are too large.
+func (r *maxDirSizeRule) Violations(root string) ([]Violation, error) {
+	directories, err := ListDirStatMatching(root, belowRoot(root, r.matcher))
+
+	var violations []Violation
+	for _, dir := range directories {
+		if dir.Size > r.limit {
+			violations = append(violations, Violation{
+				Rule:   r.Name(),
+				Path:   dir.Path,
+				Size:   dir.Size,
+				Reason: fmt.Sprintf("size %d bytes exceeds %d bytes", dir.Size, r.limit),
+			})
+		}
+	}
+	return violations, err
+}
+
+// Forbid returns a Rule that is broken by every directory below root matching
+// m, e.g. Forbid(Name("node_modules")) for a repository that must not contain
+// installed dependencies.
+func Forbid(m Matcher) Rule {
+	return &forbidRule{matcher: m}
+}
+
+// forbidRule is the Rule returned by Forbid.
+type forbidRule struct {
+	matcher Matcher
+}
+
+// Name returns a short description of the rule.
+func (r *forbidRule) Name() string {
+	return "forbidden directory"
+}
+
+// Violations returns the forbidden directories below root.
+func (r *forbidRule) Violations(root string) ([]Violation, error) {
+	directories, err := ListDirStatMatching(root, belowRoot(root, r.matcher))
+
+	var violations []Violation
+	for _, dir := range directories {
+		violations = append(violations, Violation{
+			Rule:   r.Name(),
+			Path:   dir.Path,
+			Size:   dir.Size,
+			Reason: "directory is not allowed",
+		})
+	}
+	return violations, err
+}
+
+// belowRoot returns a Matcher that matches like m, treating nil as matching
+// everything, but never matches root itself.
+func belowRoot(root string, m Matcher) Matcher {
+	return MatcherFunc(func(path string, d fs.DirEntry) bool {
+		return path != root && (m == nil || m.Match(path, d))
+	})
+}
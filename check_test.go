@@ -0,0 +1,51 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-check-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	nodeModules := filepath.Join(tmpDir, "web", "node_modules")
+	assets := filepath.Join(tmpDir, "assets")
+
+	err = os.MkdirAll(nodeModules, 0755)
+	assert.NoError(t, err)
+	err = os.MkdirAll(assets, 0755)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(assets, "logo.png"), make([]byte, 2048), 0644)
+	assert.NoError(t, err)
+
+	result, err := Check(tmpDir, MaxDirSize(4096, nil), Forbid(Name("vendor")))
+	assert.NoError(t, err)
+	assert.True(t, result.Passed)
+	assert.Empty(t, result.Violations)
+
+	result, err = Check(tmpDir, MaxDirSize(1024, nil), Forbid(Name("node_modules")))
+	assert.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Len(t, result.Violations, 2)
+
+	for _, violation := range result.Violations {
+		switch violation.Path {
+		case assets:
+			assert.Equal(t, "max directory size 1024 bytes", violation.Rule)
+			assert.Equal(t, int64(2048), violation.Size)
+		case nodeModules:
+			assert.Equal(t, "forbidden directory", violation.Rule)
+		default:
+			t.Fatalf("Unexpected violation: %+v", violation)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package go_walk
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DirectoryChurn describes how much a directory changed within a time window.
+type DirectoryChurn struct {
+	Path    string `json:"path" yaml:"path"`       // Path of the directory.
+	Written int64  `json:"written" yaml:"written"` // Bytes the directory grew by.
+	Deleted int64  `json:"deleted" yaml:"deleted"` // Bytes the directory shrank by.
+	Changes int    `json:"changes" yaml:"changes"` // Number of changes recorded.
+}
+
+// Churn returns the total number of bytes written and deleted.
+func (c DirectoryChurn) Churn() int64 {
+	return c.Written + c.Deleted
+}
+
+// churnEvent is a single size change of a directory.
+type churnEvent struct {
+	at      time.Time
+	written int64
+	deleted int64
+}
+
+// ChurnTracker accumulates the changes reported by a PollWatcher and ranks
+// directories by how many bytes were written to or deleted from them within a
+// sliding time window, pointing at runaway log writers or busy caches. Bytes
+// are estimated from the size difference between polls, so a file rewritten
+// at the same size between two polls goes unnoticed. It is safe for
+// concurrent use.
+type ChurnTracker struct {
+	mu     sync.Mutex
+	window time.Duration
+	now    func() time.Time
+	events map[string][]churnEvent
+}
+
+// NewChurnTracker returns a ChurnTracker that considers the changes recorded
+// within the last window.
+func NewChurnTracker(window time.Duration) *ChurnTracker {
+	return &ChurnTracker{
+		window: window,
+		now:    time.Now,
+		events: make(map[string][]churnEvent),
+	}
+}
+
+// Record adds a change, as delivered by PollWatcher.Changes.
+func (t *ChurnTracker) Record(change Change) {
+	var event churnEvent
+	switch change.Kind {
+	case Added:
+		event.written = change.Directory.Size
+	case Removed:
+		event.deleted = change.Previous.Size
+	default:
+		if delta := change.Directory.Size - change.Previous.Size; delta > 0 {
+			event.written = delta
+		} else {
+			event.deleted = -delta
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	event.at = t.now()
+	// Without pruning here a tracker nobody asks for leaders would grow
+	// without bound.
+	t.expire(event.at)
+	path := change.Directory.Path
+	t.events[path] = append(t.events[path], event)
+}
+
+// expire drops the events older than the window before now, and the
+// directories left without any. The caller must hold t.mu.
+func (t *ChurnTracker) expire(now time.Time) {
+	cutoff := now.Add(-t.window)
+	for path, events := range t.events {
+		// Events are recorded in order, so expired ones are at the front.
+		i := sort.Search(len(events), func(i int) bool {
+			return !events[i].at.Before(cutoff)
+		})
+		if i == len(events) {
+			delete(t.events, path)
+		} else if i > 0 {
+			t.events[path] = slices.Clone(events[i:])
+		}
+	}
+}
+
+// Leaders returns the n directories with the most churn within the window,
+// most first. An n of zero or less returns all of them.
+func (t *ChurnTracker) Leaders(n int) []DirectoryChurn {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expire(t.now())
+	var leaders []DirectoryChurn
+	for path, events := range t.events {
+		churn := DirectoryChurn{Path: path, Changes: len(events)}
+		for _, event := range events {
+			churn.Written += event.written
+			churn.Deleted += event.deleted
+		}
+		leaders = append(leaders, churn)
+	}
+
+	sort.Slice(leaders, func(i, j int) bool {
+		if leaders[i].Churn() != leaders[j].Churn() {
+			return leaders[i].Churn() > leaders[j].Churn()
+		}
+		return leaders[i].Path < leaders[j].Path
+	})
+	if n > 0 && len(leaders) > n {
+		leaders = leaders[:n]
+	}
+	return leaders
+}
@@ -0,0 +1,54 @@
+package go_walk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChurnTracker(t *testing.T) {
+	tracker := NewChurnTracker(time.Hour)
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+
+	logs := DirectoryInfo{Path: "/var/log/app", Size: 1000}
+	cache := DirectoryInfo{Path: "/home/user/.cache", Size: 5000}
+
+	tracker.Record(Change{Kind: Modified, Directory: DirectoryInfo{Path: logs.Path, Size: 900}, Previous: logs})
+
+	now = now.Add(2 * time.Hour)
+	tracker.Record(Change{Kind: Added, Directory: logs})
+	tracker.Record(Change{Kind: Modified, Directory: DirectoryInfo{Path: logs.Path, Size: 3000}, Previous: logs})
+	tracker.Record(Change{Kind: Modified, Directory: DirectoryInfo{Path: cache.Path, Size: 4500}, Previous: cache})
+	tracker.Record(Change{Kind: Removed, Directory: DirectoryInfo{Path: "/tmp/build", Size: 200}, Previous: DirectoryInfo{Path: "/tmp/build", Size: 200}})
+
+	assert.Equal(t, []DirectoryChurn{
+		{Path: logs.Path, Written: 3000, Changes: 2},
+		{Path: cache.Path, Deleted: 500, Changes: 1},
+		{Path: "/tmp/build", Deleted: 200, Changes: 1},
+	}, tracker.Leaders(0))
+	assert.Len(t, tracker.Leaders(1), 1)
+
+	now = now.Add(2 * time.Hour)
+	assert.Empty(t, tracker.Leaders(0))
+}
+
+func TestChurnTrackerRecordExpires(t *testing.T) {
+	tracker := NewChurnTracker(time.Hour)
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+
+	logs := DirectoryInfo{Path: "/var/log/app", Size: 1000}
+	for i := 0; i < 10; i++ {
+		tracker.Record(Change{Kind: Modified, Directory: DirectoryInfo{Path: logs.Path, Size: 900}, Previous: logs})
+		now = now.Add(time.Minute)
+	}
+	tracker.Record(Change{Kind: Added, Directory: DirectoryInfo{Path: "/tmp/build", Size: 200}})
+
+	// Recording alone drops what left the window, without asking for leaders
+	now = now.Add(2 * time.Hour)
+	tracker.Record(Change{Kind: Added, Directory: logs})
+	assert.Len(t, tracker.events, 1)
+	assert.Len(t, tracker.events[logs.Path], 1)
+}
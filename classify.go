@@ -0,0 +1,56 @@
+package go_walk
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// ErrorClass categorises an error that occurred during a scan.
+type ErrorClass int
+
+const (
+	// ClassOther is any error not covered by a more specific class.
+	ClassOther ErrorClass = iota
+	// ClassNotExist is a path that disappeared during the scan.
+	ClassNotExist
+	// ClassPermission is an access denied by permission bits or ownership.
+	ClassPermission
+	// ClassPolicy is an access denied by a mandatory access control policy,
+	// such as SELinux or AppArmor, although the permission bits allow it.
+	ClassPolicy
+)
+
+// String returns the name of the class.
+func (c ErrorClass) String() string {
+	switch c {
+	case ClassNotExist:
+		return "not exist"
+	case ClassPermission:
+		return "permission"
+	case ClassPolicy:
+		return "policy"
+	default:
+		return "other"
+	}
+}
+
+// Classify returns the class of err. Denials by mandatory access control
+// policies are only detected on Linux with SELinux or AppArmor enabled, and
+// are otherwise reported as ClassPermission.
+func Classify(err error) ErrorClass {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return ClassNotExist
+	case errors.Is(err, fs.ErrPermission):
+		if path, ok := errorPath(err); ok && macEnabled() {
+			info, statErr := os.Stat(path)
+			if statErr == nil && modePermits(info) {
+				return ClassPolicy
+			}
+		}
+		return ClassPermission
+	default:
+		return ClassOther
+	}
+}
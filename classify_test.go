@@ -0,0 +1,33 @@
+package go_walk
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test-classify-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	assert.Equal(t, ClassOther, Classify(errors.New("unrelated")))
+	assert.Equal(t, ClassNotExist, Classify(&fs.PathError{Op: "lstat", Path: tmpDir + "/gone", Err: fs.ErrNotExist}))
+
+	// A denial for a path whose permission bits allow access is only
+	// attributed to a policy when one is active
+	err = &fs.PathError{Op: "open", Path: tmpDir, Err: fs.ErrPermission}
+	if macEnabled() {
+		assert.Equal(t, ClassPolicy, Classify(err))
+	} else {
+		assert.Equal(t, ClassPermission, Classify(err))
+	}
+
+	assert.Equal(t, "policy", ClassPolicy.String())
+}
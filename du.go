@@ -0,0 +1,119 @@
+package go_walk
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// UsageMode selects how DiskUsage measures a directory.
+type UsageMode int
+
+const (
+	// ApparentSize sums the apparent sizes of all entries, matching
+	// "du -sb".
+	ApparentSize UsageMode = iota
+	// AllocatedSize sums the 512-byte blocks allocated to all entries,
+	// matching "du -s" expressed in bytes. On platforms that do not report
+	// allocated blocks it falls back to the apparent size.
+	AllocatedSize
+)
+
+// sysStat holds the platform specific attributes of a file.
+type sysStat struct {
+	dev    uint64 // Device the file resides on.
+	ino    uint64 // Inode number of the file.
+	nlink  uint64 // Number of hard links to the file.
+	blocks int64  // Number of 512-byte blocks allocated to the file.
+	uid    uint32 // User ID of the owner.
+	gid    uint32 // Group ID of the owner.
+}
+
+// fileID uniquely identifies a file on a system.
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// DiskUsage returns the size of dirPath computed the way GNU du computes it:
+// the directory itself and every entry below it are counted, symbolic links
+// are not followed and files with several hard links are counted once.
+func DiskUsage(dirPath string, mode UsageMode) (int64, error) {
+	return walkUsage(dirPath, func(info fs.FileInfo, st sysStat, ok bool) int64 {
+		if mode == AllocatedSize && ok {
+			return st.blocks * 512
+		}
+		return info.Size()
+	})
+}
+
+// BlockUsage returns the size of dirPath like DiskUsage, with the apparent
+// size of every entry rounded up to a multiple of blockSize. If blockSize is
+// zero or negative, the block size of the filesystem dirPath resides on is
+// used.
+func BlockUsage(dirPath string, blockSize int64) (int64, error) {
+	if blockSize <= 0 {
+		var err error
+		blockSize, err = FilesystemBlockSize(dirPath)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return walkUsage(dirPath, func(info fs.FileInfo, _ sysStat, _ bool) int64 {
+		return roundUp(info.Size(), blockSize)
+	})
+}
+
+// walkUsage sums the sizes reported by size for dirPath and every entry below
+// it, counting files with several hard links once.
+func walkUsage(dirPath string, size func(info fs.FileInfo, st sysStat, ok bool) int64) (int64, error) {
+	var total int64
+	seen := make(map[fileID]struct{})
+
+	err := filepath.WalkDir(dirPath, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		st, ok := statOf(info)
+		if ok && !entry.IsDir() && st.nlink > 1 {
+			id := fileID{dev: st.dev, ino: st.ino}
+			if _, exists := seen[id]; exists {
+				return nil
+			}
+			seen[id] = struct{}{}
+		}
+
+		total += size(info, st, ok)
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// roundUp rounds size up to the nearest multiple of blockSize.
+func roundUp(size, blockSize int64) int64 {
+	return (size + blockSize - 1) / blockSize * blockSize
+}
+
+// allocatedSize returns the space allocated on disk to the entry described by
+// info: its 512-byte blocks where the platform reports them, or else its
+// apparent size rounded up to blockSize if that is positive.
+func allocatedSize(info fs.FileInfo, blockSize int64) int64 {
+	if st, ok := statOf(info); ok {
+		return st.blocks * 512
+	}
+	if blockSize > 0 {
+		return roundUp(info.Size(), blockSize)
+	}
+	return info.Size()
+}
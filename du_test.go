@@ -0,0 +1,95 @@
+package go_walk
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gnuDU runs GNU du with args against path and returns the reported size. The
+// test is skipped if GNU du is not available.
+func gnuDU(t *testing.T, path string, args ...string) int64 {
+	t.Helper()
+
+	version, err := exec.Command("du", "--version").Output()
+	if err != nil || !strings.Contains(string(version), "GNU") {
+		t.Skip("GNU du is not available")
+	}
+
+	out, err := exec.Command("du", append(args, path)...).Output()
+	assert.NoError(t, err)
+
+	size, err := strconv.ParseInt(strings.Fields(string(out))[0], 10, 64)
+	assert.NoError(t, err)
+	return size
+}
+
+func TestDiskUsage(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-disk-usage-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	nested := filepath.Join(tmpDir, "project1", "node_modules")
+	err = os.MkdirAll(nested, 0755)
+	assert.NoError(t, err)
+
+	testFilePath := filepath.Join(nested, "test.txt")
+	err = os.WriteFile(testFilePath, []byte("test content"), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tmpDir, "large.bin"), make([]byte, 10000), 0644)
+	assert.NoError(t, err)
+
+	// Hard links are counted once and symbolic links are not followed
+	err = os.Link(testFilePath, filepath.Join(tmpDir, "hardlink.txt"))
+	assert.NoError(t, err)
+	err = os.Symlink(nested, filepath.Join(tmpDir, "symlink"))
+	assert.NoError(t, err)
+
+	size, err := DiskUsage(tmpDir, ApparentSize)
+	assert.NoError(t, err)
+	assert.Equal(t, gnuDU(t, tmpDir, "-sb"), size)
+
+	size, err = DiskUsage(tmpDir, AllocatedSize)
+	assert.NoError(t, err)
+	assert.Equal(t, gnuDU(t, tmpDir, "-s", "-B1"), size)
+}
+
+func TestBlockUsage(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-block-usage-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	err = os.WriteFile(filepath.Join(tmpDir, "small.txt"), []byte("a"), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tmpDir, "large.bin"), make([]byte, 4097), 0644)
+	assert.NoError(t, err)
+
+	dirStat, err := os.Stat(tmpDir)
+	assert.NoError(t, err)
+	dirSize := roundUp(dirStat.Size(), 4096)
+
+	size, err := BlockUsage(tmpDir, 4096)
+	assert.NoError(t, err)
+	assert.Equal(t, dirSize+4096+8192, size)
+
+	blockSize, err := FilesystemBlockSize(tmpDir)
+	assert.NoError(t, err)
+	assert.Greater(t, blockSize, int64(0))
+
+	size, err = BlockUsage(tmpDir, 0)
+	assert.NoError(t, err)
+	assert.Zero(t, size%blockSize)
+}
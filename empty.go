@@ -0,0 +1,74 @@
+package go_walk
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// emptyBatch is how many entries IsDirEmptyRecursive reads from a directory
+// at a time.
+const emptyBatch = 64
+
+// IsDirEmptyRecursive reports whether the directory at path contains nothing
+// but, possibly, directories that are themselves empty. It stops at the first
+// file, symbolic link or other non-directory entry found, reading directories
+// a few entries at a time, so that a directory with content is recognised
+// without walking all of it.
+func IsDirEmptyRecursive(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if !info.IsDir() {
+		return false, errors.New("the path provided is not a directory")
+	}
+	return isDirEmpty(path)
+}
+
+// isDirEmpty reports whether the directory at path contains no files,
+// reading its subdirectories depth first.
+func isDirEmpty(path string) (bool, error) {
+	subdirs, err := subdirsIfNoFiles(path)
+	if err != nil || subdirs == nil {
+		return false, err
+	}
+
+	// The directory is closed before descending, so that only one handle is
+	// open at a time however deep the tree.
+	for _, subdir := range subdirs {
+		if empty, err := isDirEmpty(subdir); err != nil || !empty {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// subdirsIfNoFiles returns the paths of the subdirectories of the directory
+// at path, or nil if it contains anything else. Subdirectories are only
+// looked into once the directory itself has been found to hold no files.
+func subdirsIfNoFiles(path string) ([]string, error) {
+	dir, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	subdirs := []string{}
+	for {
+		entries, err := dir.ReadDir(emptyBatch)
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				return nil, nil
+			}
+			subdirs = append(subdirs, filepath.Join(path, entry.Name()))
+		}
+		if err == io.EOF {
+			return subdirs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
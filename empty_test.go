@@ -0,0 +1,45 @@
+package go_walk
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/akshaybabloo/go-walk/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDirEmptyRecursive(t *testing.T) {
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"empty/":                 fixtures.Dir(),
+		"nested/a/b/":            fixtures.Dir(),
+		"nested/c/":              fixtures.Dir(),
+		"deep/a/b/c/file.txt":    {Data: []byte("test")},
+		"deep/d/":                fixtures.Dir(),
+		"empty-file/a/empty.txt": fixtures.File(0),
+		"link/a/current":         fixtures.Symlink("missing"),
+	})
+
+	tests := []struct {
+		dir  string
+		want bool
+	}{
+		{"empty", true},
+		{"nested", true},
+		{"deep", false},
+		{"empty-file", false},
+		{"link", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			empty, err := IsDirEmptyRecursive(filepath.Join(tmpDir, tt.dir))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, empty)
+		})
+	}
+
+	_, err := IsDirEmptyRecursive(filepath.Join(tmpDir, "deep", "a", "b", "c", "file.txt"))
+	assert.Error(t, err)
+
+	_, err = IsDirEmptyRecursive(filepath.Join(tmpDir, "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package go_walk
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// PathTooLongError reports a path that exceeds the length supported by the
+// operating system. Scans skip such paths.
+type PathTooLongError struct {
+	Path   string // The path that is too long.
+	Parent string // The directory containing the path.
+	Err    error  // The underlying error.
+}
+
+// Error returns a description of the error.
+func (e *PathTooLongError) Error() string {
+	return "path too long in " + e.Parent + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *PathTooLongError) Unwrap() error {
+	return e.Err
+}
+
+// WalkError records an error that occurred while processing a path. The
+// errors in an ErrorList are WalkErrors wherever the path is known.
+type WalkError struct {
+	Path string // The path being processed.
+	Op   string // The operation that failed, such as "open" or "lstat".
+	Err  error  // The underlying error.
+}
+
+// Error returns a description of the error.
+func (e *WalkError) Error() string {
+	return e.Op + " " + e.Path + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *WalkError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorList collects the errors that occurred while processing a directory
+// tree. Functions that return an ErrorList still return the results they
+// could compute.
+type ErrorList []error
+
+// Error returns the messages of all errors in the list.
+func (l ErrorList) Error() string {
+	messages := make([]string, 0, len(l))
+	for _, err := range l {
+		messages = append(messages, err.Error())
+	}
+	return "errors occurred during directory processing: " + strings.Join(messages, "; ")
+}
+
+// Unwrap returns the errors in the list, so that errors.Is and errors.As
+// match any of them, such as errors.Is(err, fs.ErrPermission).
+func (l ErrorList) Unwrap() []error {
+	return l
+}
+
+// add appends err to the list, flattening it if it holds several errors, like
+// an ErrorList or the result of errors.Join.
+func (l *ErrorList) add(err error) {
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range multi.Unwrap() {
+			l.add(err)
+		}
+		return
+	}
+	*l = append(*l, classifyError(err))
+}
+
+// classifyError converts err to a more specific error type where one exists.
+func classifyError(err error) error {
+	var pathErr *fs.PathError
+	if errors.Is(err, syscall.ENAMETOOLONG) && errors.As(err, &pathErr) {
+		return &PathTooLongError{
+			Path:   pathErr.Path,
+			Parent: filepath.Dir(pathErr.Path),
+			Err:    err,
+		}
+	}
+	if pathErr, ok := err.(*fs.PathError); ok {
+		return &WalkError{Path: pathErr.Path, Op: pathErr.Op, Err: pathErr.Err}
+	}
+	return err
+}
+
+// errorPath returns the path err occurred at, if it records one.
+func errorPath(err error) (string, bool) {
+	var walkErr *WalkError
+	if errors.As(err, &walkErr) {
+		return walkErr.Path, true
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Path, true
+	}
+	return "", false
+}
+
+// err returns the list as an error, or nil if it is empty.
+func (l ErrorList) err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
@@ -0,0 +1,76 @@
+package go_walk
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathTooLong(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-path-too-long-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	// Build a tree deeper than the operating system allows paths to be by
+	// working relative to the current directory
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	defer func() {
+		err := os.Chdir(wd)
+		assert.NoError(t, err)
+	}()
+
+	err = os.Chdir(tmpDir)
+	assert.NoError(t, err)
+
+	name := strings.Repeat("d", 200)
+	for i := 0; i < 40; i++ {
+		err = os.Mkdir(name, 0755)
+		assert.NoError(t, err)
+		err = os.Chdir(name)
+		assert.NoError(t, err)
+	}
+
+	_, err = ListDirStat(tmpDir, WithKeywords("node_modules"))
+	if err == nil {
+		t.Skip("the operating system supports paths of this length")
+	}
+
+	var errs ErrorList
+	assert.True(t, errors.As(err, &errs))
+
+	var tooLong *PathTooLongError
+	assert.True(t, errors.As(errs[0], &tooLong))
+	assert.True(t, strings.HasPrefix(tooLong.Path, filepath.Join(tmpDir, name)))
+	assert.Equal(t, filepath.Dir(tooLong.Path), tooLong.Parent)
+}
+
+func TestErrorListUnwrap(t *testing.T) {
+	notExist := &fs.PathError{Op: "open", Path: "/missing", Err: fs.ErrNotExist}
+	permission := &fs.PathError{Op: "open", Path: "/root", Err: fs.ErrPermission}
+
+	var errs ErrorList
+	errs.add(notExist)
+	errs.add(errors.Join(permission, ErrorList{notExist}))
+	assert.Len(t, errs, 3)
+
+	err := errs.err()
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.NotErrorIs(t, err, fs.ErrExist)
+
+	var walkErr *WalkError
+	assert.ErrorAs(t, err, &walkErr)
+	assert.Equal(t, "/missing", walkErr.Path)
+	assert.Equal(t, "open", walkErr.Op)
+	assert.Equal(t, "open /missing: file does not exist", walkErr.Error())
+}
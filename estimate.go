@@ -0,0 +1,98 @@
+package go_walk
+
+import (
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// estimateProbes is the number of random paths EstimateScanTime samples.
+const estimateProbes = 32
+
+// ScanEstimate holds the extrapolated cost of scanning a directory tree.
+type ScanEstimate struct {
+	Directories int64         // Estimated number of directories, including the root.
+	Files       int64         // Estimated number of files.
+	Duration    time.Duration // Estimated time to list the whole tree and read the metadata of every entry sequentially.
+}
+
+// EstimateScanTime estimates the size of the tree rooted at root, and how long
+// it takes to walk, without walking all of it. It follows a number of random
+// paths from root down to a leaf directory and extrapolates from the branching
+// factors along the way (Knuth's estimator). The estimate is exact for evenly
+// shaped trees and becomes rougher the more lopsided the tree is.
+func EstimateScanTime(root string) (ScanEstimate, error) {
+	type dirSample struct {
+		subdirs []string
+		files   int
+	}
+
+	samples := make(map[string]dirSample)
+	var readTime time.Duration
+
+	sample := func(path string) (dirSample, error) {
+		if s, exists := samples[path]; exists {
+			return s, nil
+		}
+
+		// Scans read the metadata of every entry as well as listing it, so
+		// both are timed.
+		start := time.Now()
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			readTime += time.Since(start)
+			return dirSample{}, err
+		}
+		for _, entry := range entries {
+			_, _ = entry.Info()
+		}
+		readTime += time.Since(start)
+
+		var s dirSample
+		for _, entry := range entries {
+			if entry.IsDir() {
+				s.subdirs = append(s.subdirs, filepath.Join(path, entry.Name()))
+			} else {
+				s.files++
+			}
+		}
+		samples[path] = s
+		return s, nil
+	}
+
+	if _, err := sample(root); err != nil {
+		return ScanEstimate{}, err
+	}
+
+	var directories, files float64
+	for i := 0; i < estimateProbes; i++ {
+		weight := 1.0
+		path := root
+		for {
+			s, err := sample(path)
+			if err != nil {
+				// Unreadable directories are treated as leaves.
+				directories += weight
+				break
+			}
+
+			directories += weight
+			files += weight * float64(s.files)
+			if len(s.subdirs) == 0 {
+				break
+			}
+
+			weight *= float64(len(s.subdirs))
+			path = s.subdirs[rand.IntN(len(s.subdirs))]
+		}
+	}
+
+	estimate := ScanEstimate{
+		Directories: int64(directories/estimateProbes + 0.5),
+		Files:       int64(files/estimateProbes + 0.5),
+	}
+	estimate.Duration = readTime / time.Duration(len(samples)) * time.Duration(estimate.Directories)
+
+	return estimate, nil
+}
@@ -0,0 +1,41 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateScanTime(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-estimate-scan-time-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	// An evenly shaped tree of 3 projects with 2 packages each, and one file
+	// in every package
+	for _, project := range []string{"project1", "project2", "project3"} {
+		for _, pkg := range []string{"package1", "package2"} {
+			dir := filepath.Join(tmpDir, project, pkg)
+			err = os.MkdirAll(dir, 0755)
+			assert.NoError(t, err)
+			err = os.WriteFile(filepath.Join(dir, "test.txt"), []byte("test"), 0644)
+			assert.NoError(t, err)
+		}
+	}
+
+	estimate, err := EstimateScanTime(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), estimate.Directories)
+	assert.Equal(t, int64(6), estimate.Files)
+	assert.Greater(t, estimate.Duration, time.Duration(0))
+
+	_, err = EstimateScanTime(filepath.Join(tmpDir, "does-not-exist"))
+	assert.Error(t, err)
+}
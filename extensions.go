@@ -0,0 +1,40 @@
+package go_walk
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ExtStat describes the files of a directory sharing an extension.
+type ExtStat struct {
+	Count int   `json:"count" yaml:"count"` // Number of files.
+	Size  int64 `json:"size" yaml:"size"`   // Combined size of the files in bytes.
+}
+
+// WithExtensionStats breaks the files of every directory found by a scan
+// down by extension in ByExtension, which is left empty otherwise to save the
+// bookkeeping.
+func WithExtensionStats() Option {
+	return func(o *options) {
+		o.extensions = true
+	}
+}
+
+// extensionOf returns the key of the file called name in ByExtension: its
+// lower case extension including the dot, empty if it has none.
+func extensionOf(name string) string {
+	return strings.ToLower(filepath.Ext(name))
+}
+
+// addExtension adds count files with extension ext, together size bytes, to
+// byExt, allocating it if nil, and returns it.
+func addExtension(byExt map[string]ExtStat, ext string, count int, size int64) map[string]ExtStat {
+	if byExt == nil {
+		byExt = make(map[string]ExtStat)
+	}
+	stat := byExt[ext]
+	stat.Count += count
+	stat.Size += size
+	byExt[ext] = stat
+	return byExt
+}
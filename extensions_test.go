@@ -0,0 +1,40 @@
+package go_walk
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/akshaybabloo/go-walk/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListDirStatWithExtensionStats(t *testing.T) {
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"cache/thumbs/a.jpg":      fixtures.File(300),
+		"cache/thumbs/b.JPG":      fixtures.File(500),
+		"cache/index.db":          fixtures.File(150),
+		"cache/thumbs/README":     fixtures.File(50),
+		"other/node_modules/x.js": fixtures.File(4),
+	})
+
+	directories, err := ListDirStat(tmpDir, WithKeywords("cache"))
+	assert.NoError(t, err)
+	if assert.Len(t, directories, 1) {
+		assert.Nil(t, directories[0].ByExtension)
+	}
+
+	directories, err = ListDirStat(tmpDir, WithKeywords("cache", "thumbs"), WithExtensionStats(), WithSort(ByPath, Ascending))
+	assert.NoError(t, err)
+	if assert.Len(t, directories, 2) {
+		assert.Equal(t, filepath.Join(tmpDir, "cache"), directories[0].Path)
+		assert.Equal(t, map[string]ExtStat{
+			".jpg": {Count: 2, Size: 800},
+			".db":  {Count: 1, Size: 150},
+			"":     {Count: 1, Size: 50},
+		}, directories[0].ByExtension)
+		assert.Equal(t, map[string]ExtStat{
+			".jpg": {Count: 2, Size: 800},
+			"":     {Count: 1, Size: 50},
+		}, directories[1].ByExtension)
+	}
+}
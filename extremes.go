@@ -0,0 +1,55 @@
+package go_walk
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// PathExtremes records the deepest path and the longest names encountered
+// during a scan, which are what tools with fixed limits tend to break on.
+type PathExtremes struct {
+	DeepestPath       string `json:"deepest_path" yaml:"deepest_path"`               // Path nested deepest below the root.
+	MaxDepth          int    `json:"max_depth" yaml:"max_depth"`                     // Depth of DeepestPath, with the root's children at depth 1.
+	LongestName       string `json:"longest_name" yaml:"longest_name"`               // Path of the entry with the longest base name.
+	LongestNameLength int    `json:"longest_name_length" yaml:"longest_name_length"` // Length in bytes of that base name.
+	LongestPath       string `json:"longest_path" yaml:"longest_path"`               // Longest path encountered.
+	LongestPathLength int    `json:"longest_path_length" yaml:"longest_path_length"` // Length in bytes of LongestPath.
+}
+
+// FindPathExtremes walks root and returns the deepest path and the longest
+// file name and path below it. Lengths are in bytes, as most filesystem
+// limits are. Returns aggregated errors alongside the extremes if they occur.
+func FindPathExtremes(root string) (PathExtremes, error) {
+	var extremes PathExtremes
+	var errs ErrorList
+
+	root = filepath.Clean(root)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			errs.add(err)
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+
+		if depth := depthBelow(root, path); depth > extremes.MaxDepth {
+			extremes.DeepestPath = path
+			extremes.MaxDepth = depth
+		}
+		if n := len(entry.Name()); n > extremes.LongestNameLength {
+			extremes.LongestName = path
+			extremes.LongestNameLength = n
+		}
+		if n := len(path); n > extremes.LongestPathLength {
+			extremes.LongestPath = path
+			extremes.LongestPathLength = n
+		}
+		return nil
+	})
+	if err != nil {
+		errs.add(err)
+	}
+
+	return extremes, errs.err()
+}
@@ -0,0 +1,39 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindPathExtremes(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-find-path-extremes-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	deep := filepath.Join(tmpDir, "node_modules", "a", "node_modules", "b", "node_modules", "c")
+	err = os.MkdirAll(deep, 0755)
+	assert.NoError(t, err)
+
+	longName := filepath.Join(tmpDir, strings.Repeat("x", 120)+".txt")
+	err = os.WriteFile(longName, []byte("test"), 0644)
+	assert.NoError(t, err)
+
+	extremes, err := FindPathExtremes(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, PathExtremes{
+		DeepestPath:       deep,
+		MaxDepth:          6,
+		LongestName:       longName,
+		LongestNameLength: 124,
+		LongestPath:       longName,
+		LongestPathLength: len(longName),
+	}, extremes)
+}
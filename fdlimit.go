@@ -0,0 +1,67 @@
+package go_walk
+
+import (
+	"sync"
+	"time"
+)
+
+// fdRetries is how many times an operation that ran out of file descriptors
+// is retried before its error is reported.
+const fdRetries = 8
+
+// fdBackoff and fdMaxBackoff are the first and longest waits before retrying
+// an operation that ran out of file descriptors.
+const (
+	fdBackoff    = 10 * time.Millisecond
+	fdMaxBackoff = time.Second
+)
+
+// fdLimiter bounds the directory handles a scan holds open at once. Its limit
+// starts out as set by WithMaxOpenFDs and shrinks whenever the process or
+// system runs out of file descriptors, so that a scan degrades to fewer
+// concurrent reads instead of failing.
+type fdLimiter struct {
+	mu    sync.Mutex
+	cond  sync.Cond
+	limit int // Most handles open at once, unlimited if zero.
+	open  int // Handles open.
+}
+
+// newFDLimiter returns an fdLimiter allowing limit handles at once, any
+// number if zero.
+func newFDLimiter(limit int) *fdLimiter {
+	l := &fdLimiter{limit: limit}
+	l.cond.L = &l.mu
+	return l
+}
+
+// acquire waits until a handle may be opened and accounts for it.
+func (l *fdLimiter) acquire() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.limit > 0 && l.open >= l.limit {
+		l.cond.Wait()
+	}
+	l.open++
+}
+
+// release accounts for a handle having been closed.
+func (l *fdLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.open--
+	l.cond.Signal()
+}
+
+// backOff halves the handles allowed open at once, down to one, and waits
+// before the retry numbered attempt, starting from zero, of an operation that
+// ran out of file descriptors. The caller must not hold a handle.
+func (l *fdLimiter) backOff(attempt int) {
+	l.mu.Lock()
+	if limit := max((l.open+1)/2, 1); l.limit == 0 || limit < l.limit {
+		l.limit = limit
+	}
+	l.mu.Unlock()
+
+	time.Sleep(min(fdBackoff<<attempt, fdMaxBackoff))
+}
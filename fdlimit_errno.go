@@ -0,0 +1,14 @@
+//go:build !plan9
+
+package go_walk
+
+import (
+	"errors"
+	"syscall"
+)
+
+// tooManyOpenFiles reports whether err is the result of the process, EMFILE,
+// or the system, ENFILE, running out of file descriptors.
+func tooManyOpenFiles(err error) bool {
+	return errors.Is(err, syscall.EMFILE) || errors.Is(err, syscall.ENFILE)
+}
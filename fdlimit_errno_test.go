@@ -0,0 +1,19 @@
+//go:build !plan9
+
+package go_walk
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTooManyOpenFiles(t *testing.T) {
+	assert.True(t, tooManyOpenFiles(&os.PathError{Op: "open", Path: "/tmp", Err: syscall.EMFILE}))
+	assert.True(t, tooManyOpenFiles(fmt.Errorf("listing: %w", syscall.ENFILE)))
+	assert.False(t, tooManyOpenFiles(os.ErrPermission))
+	assert.False(t, tooManyOpenFiles(nil))
+}
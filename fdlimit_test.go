@@ -0,0 +1,26 @@
+package go_walk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFDLimiterBackOff(t *testing.T) {
+	l := newFDLimiter(0)
+	for i := 0; i < 8; i++ {
+		l.acquire()
+	}
+
+	// Running out with eight handles open, and a ninth failing, allows
+	// four.
+	l.backOff(0)
+	assert.Equal(t, 4, l.limit)
+
+	// A higher limit never replaces a lower one.
+	for i := 0; i < 7; i++ {
+		l.release()
+	}
+	l.backOff(0)
+	assert.Equal(t, 1, l.limit)
+}
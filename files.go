@@ -0,0 +1,91 @@
+package go_walk
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"time"
+)
+
+// FileInfo holds metadata about a file.
+type FileInfo struct {
+	Path         string    `json:"path" yaml:"path"`                   // Path of the file.
+	Size         int64     `json:"size" yaml:"size"`                   // Size of the file in bytes.
+	LastModified time.Time `json:"last_modified" yaml:"last_modified"` // When the file was last modified.
+	Extension    string    `json:"extension" yaml:"extension"`         // Lower case extension of the file name including the dot, as in ByExtension, empty if it has none.
+}
+
+// ListFileStat lists the files within root and returns their metadata. The
+// keywords given by WithKeywords select files by name according to
+// WithMatchMode, and the directories searched are walked as ListDirStat walks
+// them, honouring WithExclude, WithMaxDepth, WithOneFileSystem and
+// WithMiddleware. Symbolic links to files are listed only when followed with
+// WithSymlinks(FollowSymlinks) or WithFollowSymlinks. Without options all
+// files are listed. Returns aggregated errors alongside the files found
+// according to the ErrorPolicy.
+func ListFileStat(root string, opts ...Option) ([]FileInfo, error) {
+	o := newOptions(opts...)
+	pathStat, err := o.stat(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if !pathStat.IsDir() {
+		return nil, errors.New("the path provided is not a directory")
+	}
+
+	m, err := o.matches()
+	if err != nil {
+		return nil, err
+	}
+	// The keywords select files, so every directory is walked.
+	w, err := newScanWalker(root, o, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []FileInfo
+	var errs ErrorList
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	report := newReporter(o, errs.add, cancel)
+
+	w.onFile = func(path string, entry fs.DirEntry) {
+		if m != nil && !m.Match(path, entry) {
+			return
+		}
+
+		var info fs.FileInfo
+		var err error
+		if entry.Type()&fs.ModeSymlink != 0 {
+			if o.symlinks != FollowSymlinks && !o.followed(path) {
+				return
+			}
+			if info, err = o.stat(path); err != nil || info.IsDir() {
+				// Dangling links and links to directories are not files.
+				return
+			}
+		} else if info, err = entry.Info(); err != nil {
+			report(err)
+			return
+		}
+
+		files = append(files, FileInfo{
+			Path:         path,
+			Size:         info.Size(),
+			LastModified: info.ModTime(),
+			Extension:    extensionOf(path),
+		})
+	}
+
+	err = w.walk(ctx, report, func(string, fs.DirEntry) bool { return true })
+	if err != nil {
+		report(err)
+	}
+
+	if o.errorPolicy == FailFast && len(errs) > 0 {
+		return files, errs[0]
+	}
+	return files, errs.err()
+}
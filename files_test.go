@@ -0,0 +1,109 @@
+package go_walk
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/akshaybabloo/go-walk/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListFileStat(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-list-file-stat-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	logs := filepath.Join(tmpDir, "logs")
+	nested := filepath.Join(tmpDir, "src", "pkg")
+	for _, dir := range []string{logs, nested} {
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(t, err)
+	}
+
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	files := map[string]int{
+		filepath.Join(tmpDir, "Makefile"):       10,
+		filepath.Join(logs, "app.log"):          300,
+		filepath.Join(nested, "walk.go"):        20,
+		filepath.Join(nested, "archive.tar.gz"): 40,
+	}
+	for path, size := range files {
+		err = os.WriteFile(path, make([]byte, size), 0644)
+		assert.NoError(t, err)
+		err = os.Chtimes(path, modTime, modTime)
+		assert.NoError(t, err)
+	}
+
+	all, err := ListFileStat(tmpDir)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []FileInfo{
+		{Path: filepath.Join(tmpDir, "Makefile"), Size: 10, LastModified: modTime, Extension: ""},
+		{Path: filepath.Join(logs, "app.log"), Size: 300, LastModified: modTime, Extension: ".log"},
+		{Path: filepath.Join(nested, "walk.go"), Size: 20, LastModified: modTime, Extension: ".go"},
+		{Path: filepath.Join(nested, "archive.tar.gz"), Size: 40, LastModified: modTime, Extension: ".gz"},
+	}, all)
+
+	paths := func(files []FileInfo) []string {
+		var result []string
+		for _, file := range files {
+			result = append(result, file.Path)
+		}
+		return result
+	}
+
+	matched, err := ListFileStat(tmpDir, WithKeywords("*.go", "*.log"), WithMatchMode(MatchGlob))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{filepath.Join(logs, "app.log"), filepath.Join(nested, "walk.go")}, paths(matched))
+
+	shallow, err := ListFileStat(tmpDir, WithMaxDepth(1), WithExclude("logs"))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{filepath.Join(tmpDir, "Makefile")}, paths(shallow))
+}
+
+func TestListFileStatWithScanOptions(t *testing.T) {
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"main.go":          fixtures.File(10),
+		"linked.go":        fixtures.Symlink("main.go"),
+		"private/inner.go": fixtures.File(20),
+	})
+
+	// Treats private as unreadable
+	deny := denying("private")
+
+	files, err := ListFileStat(tmpDir, WithMiddleware(deny))
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	assert.Len(t, files, 1)
+
+	files, err = ListFileStat(tmpDir, WithMiddleware(deny), WithErrorPolicy(Ignore))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	// The files found before the error are kept
+	files, err = ListFileStat(tmpDir, WithMiddleware(deny), WithErrorPolicy(FailFast))
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	assert.Len(t, files, 1)
+
+	files, err = ListFileStat(tmpDir, WithFollowSymlinks("linked.go"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 3)
+}
+
+func TestListFileStatExtension(t *testing.T) {
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"photo.JPG": fixtures.File(10),
+	})
+
+	// Lower case, as in ByExtension
+	files, err := ListFileStat(tmpDir)
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, ".jpg", files[0].Extension)
+	}
+}
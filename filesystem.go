@@ -0,0 +1,19 @@
+package go_walk
+
+// fsStat holds the attributes of the filesystem a path resides on.
+type fsStat struct {
+	blockSize int64  // Allocation unit of the filesystem in bytes.
+	total     uint64 // Total capacity in bytes.
+	free      uint64 // Free space in bytes.
+	available uint64 // Free space available to unprivileged users in bytes.
+}
+
+// FilesystemBlockSize returns the allocation unit, in bytes, of the
+// filesystem that path resides on.
+func FilesystemBlockSize(path string) (int64, error) {
+	st, err := statFS(path)
+	if err != nil {
+		return 0, err
+	}
+	return st.blockSize, nil
+}
@@ -0,0 +1,65 @@
+package go_walk
+
+import "time"
+
+// WithMinSize leaves out of the results of a scan the directories whose
+// files add up to less than size bytes, such as WithMinSize(100*walk.MB).
+// The directories left out are still measured and counted towards the size of
+// the directories containing them.
+func WithMinSize(size int64) Option {
+	return func(o *options) {
+		o.minSize = size
+	}
+}
+
+// WithOlderThan leaves out of the results of a scan the directories with a
+// file in them modified within d, such as WithOlderThan(30*24*time.Hour),
+// keeping those whose contents have not been touched since.
+func WithOlderThan(d time.Duration) Option {
+	return func(o *options) {
+		o.olderThan = d
+	}
+}
+
+// WithModifiedAfter leaves out of the results of a scan the directories with
+// no file in them modified after t, keeping only those that changed since,
+// such as for a report of what changed this week.
+func WithModifiedAfter(t time.Time) Option {
+	return func(o *options) {
+		o.modifiedAfter = t
+	}
+}
+
+// WithModifiedBefore leaves out of the results of a scan the directories with
+// a file in them modified at or after t. Together with WithModifiedAfter it
+// keeps the directories whose newest file was modified within a time range.
+func WithModifiedBefore(t time.Time) Option {
+	return func(o *options) {
+		o.modifiedBefore = t
+	}
+}
+
+// old reports whether t is at least as long ago as WithOlderThan asks for,
+// which any time is without it.
+func (o *options) old(t time.Time) bool {
+	return o.olderThan <= 0 || time.Since(t) >= o.olderThan
+}
+
+// kept reports whether dir passes the filters set by WithMinSize,
+// WithOlderThan, WithModifiedAfter and WithModifiedBefore. Directories are
+// dated by their newest file, or by LastModified if they have none.
+func (o *options) kept(dir DirectoryInfo) bool {
+	if o.minSize > 0 && dir.Size < o.minSize {
+		return false
+	}
+	if !o.old(dir.lastChanged()) {
+		return false
+	}
+	if !o.modifiedAfter.IsZero() && !dir.lastChanged().After(o.modifiedAfter) {
+		return false
+	}
+	if !o.modifiedBefore.IsZero() && !dir.lastChanged().Before(o.modifiedBefore) {
+		return false
+	}
+	return true
+}
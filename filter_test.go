@@ -0,0 +1,82 @@
+package go_walk
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/akshaybabloo/go-walk/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListDirStatWithFilters(t *testing.T) {
+	old := 60 * 24 * time.Hour
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"small/node_modules/index.js": {Data: []byte("test")},
+		"big/node_modules/index.js":   fixtures.File(2 * KB),
+		"stale/node_modules/index.js": fixtures.File(2 * KB).Aged(old),
+		"stale/node_modules/":         fixtures.Dir().Aged(old),
+	})
+	big := filepath.Join(tmpDir, "big", "node_modules")
+	stale := filepath.Join(tmpDir, "stale", "node_modules")
+
+	directories, err := ListDirStat(tmpDir, WithKeywords("node_modules"), WithMinSize(KB), WithDeterministicOrder())
+	assert.NoError(t, err)
+	if assert.Len(t, directories, 2) {
+		assert.Equal(t, big, directories[0].Path)
+		assert.Equal(t, stale, directories[1].Path)
+	}
+
+	directories, err = ListDirStat(tmpDir, WithKeywords("node_modules"), WithOlderThan(30*24*time.Hour))
+	assert.NoError(t, err)
+	if assert.Len(t, directories, 1) {
+		assert.Equal(t, stale, directories[0].Path)
+	}
+
+	directories, err = ListDirStat(tmpDir, WithMinSize(TB))
+	assert.NoError(t, err)
+	assert.Empty(t, directories)
+
+	// Directories left out still count towards the ones containing them.
+	directories, err = ListDirStat(tmpDir, WithMinSize(KB), WithDeterministicOrder())
+	assert.NoError(t, err)
+	if assert.Len(t, directories, 5) {
+		assert.Equal(t, tmpDir, directories[0].Path)
+		assert.Equal(t, 4*KB+4, directories[0].Size)
+	}
+}
+
+func TestListDirStatWithModifiedRange(t *testing.T) {
+	now := time.Now()
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"recent/node_modules/index.js": fixtures.File(4).Aged(2 * 24 * time.Hour),
+		"recent/node_modules/":         fixtures.Dir().Aged(20 * 24 * time.Hour),
+		"month/node_modules/index.js":  fixtures.File(4).Aged(20 * 24 * time.Hour),
+		"month/node_modules/":          fixtures.Dir().Aged(20 * 24 * time.Hour),
+		"year/node_modules/index.js":   fixtures.File(4).Aged(300 * 24 * time.Hour),
+		"year/node_modules/":           fixtures.Dir().Aged(300 * 24 * time.Hour),
+	})
+	path := func(project string) string {
+		return filepath.Join(tmpDir, project, "node_modules")
+	}
+
+	// Directories are dated by the newest file within them.
+	directories, err := ListDirStat(tmpDir, WithKeywords("node_modules"), WithModifiedAfter(now.Add(-7*24*time.Hour)))
+	assert.NoError(t, err)
+	if assert.Len(t, directories, 1) {
+		assert.Equal(t, path("recent"), directories[0].Path)
+	}
+
+	directories, err = ListDirStat(tmpDir, WithKeywords("node_modules"), WithModifiedBefore(now.Add(-7*24*time.Hour)), WithSort(ByPath, Ascending))
+	assert.NoError(t, err)
+	if assert.Len(t, directories, 2) {
+		assert.Equal(t, path("month"), directories[0].Path)
+		assert.Equal(t, path("year"), directories[1].Path)
+	}
+
+	directories, err = ListDirStat(tmpDir, WithKeywords("node_modules"), WithModifiedAfter(now.Add(-60*24*time.Hour)), WithModifiedBefore(now.Add(-7*24*time.Hour)))
+	assert.NoError(t, err)
+	if assert.Len(t, directories, 1) {
+		assert.Equal(t, path("month"), directories[0].Path)
+	}
+}
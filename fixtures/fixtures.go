@@ -0,0 +1,187 @@
+// Package fixtures builds directory trees for tests from a declarative
+// description, so that code reading them, such as go_walk.ListDirStat, can be
+// tested without creating every directory and file by hand.
+package fixtures
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tree describes a directory tree, mapping slash-separated paths relative to
+// its root to what is found there. Paths ending in a slash are directories.
+// Parent directories are created as needed, so only empty directories have to
+// be listed.
+type Tree map[string]Entry
+
+// Entry describes a file, directory or link within a Tree. The zero value is
+// an empty file, or an empty directory for paths ending in a slash.
+type Entry struct {
+	Data     []byte      // Contents of the file, Size zero bytes if nil.
+	Size     int64       // Size of the file, if Data is nil.
+	Mode     os.FileMode // Permissions, 0644 for files and 0755 for directories if zero.
+	ModTime  time.Time   // Modification time, the time of creation if zero.
+	Symlink  string      // Makes the entry a symbolic link pointing to Symlink.
+	Hardlink string      // Makes the entry a hard link to the file at this path of the Tree.
+}
+
+// Sizes in bytes, for use with Entry.Size.
+const (
+	KiB int64 = 1 << (10 * (iota + 1))
+	MiB
+	GiB
+)
+
+// File returns an Entry for a file of size bytes.
+func File(size int64) Entry {
+	return Entry{Size: size}
+}
+
+// Dir returns an Entry for an empty directory.
+func Dir() Entry {
+	return Entry{}
+}
+
+// Symlink returns an Entry for a symbolic link pointing to target.
+func Symlink(target string) Entry {
+	return Entry{Symlink: target}
+}
+
+// Hardlink returns an Entry for a hard link to the file at path in the Tree.
+func Hardlink(path string) Entry {
+	return Entry{Hardlink: path}
+}
+
+// Aged returns e last modified d ago.
+func (e Entry) Aged(d time.Duration) Entry {
+	e.ModTime = time.Now().Add(-d)
+	return e
+}
+
+// Create builds tree within the existing directory dir. Files and
+// directories come first, then hard links and symbolic links, so links can
+// point to any entry of the tree. Modification times and the permissions of
+// directories are set last, deepest first, so that creating entries does not
+// change them.
+func Create(dir string, tree Tree) error {
+	paths := make([]string, 0, len(tree))
+	for p := range tree {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+
+	for _, create := range []func(dir, p string, e Entry) error{createEntry, createHardlink, createSymlink} {
+		for _, p := range paths {
+			if err := create(dir, p, tree[p]); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Children sort after their parents, so finishing in reverse order
+	// leaves what has been set on the parents alone.
+	for i := len(paths) - 1; i >= 0; i-- {
+		p, e := paths[i], tree[paths[i]]
+		if e.Symlink != "" {
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(p))
+		if isDir(p) && e.Mode != 0 {
+			if err := os.Chmod(path, e.Mode); err != nil {
+				return err
+			}
+		}
+		if !e.ModTime.IsZero() {
+			if err := os.Chtimes(path, e.ModTime, e.ModTime); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isDir reports whether the path p of a Tree names a directory.
+func isDir(p string) bool {
+	return strings.HasSuffix(p, "/")
+}
+
+// createEntry creates the file or directory at p within dir described by e,
+// or the parent directory of links.
+func createEntry(dir, p string, e Entry) error {
+	path := filepath.Join(dir, filepath.FromSlash(p))
+	if isDir(p) {
+		return os.MkdirAll(path, 0755)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if e.Symlink != "" || e.Hardlink != "" {
+		return nil
+	}
+
+	mode := e.Mode
+	if mode == 0 {
+		mode = 0644
+	}
+	data := e.Data
+	if data == nil {
+		data = make([]byte, e.Size)
+	}
+	if err := os.WriteFile(path, data, mode); err != nil {
+		return err
+	}
+	// WriteFile only sets the permissions, less the umask, of new files.
+	return os.Chmod(path, mode)
+}
+
+// createHardlink creates the hard link at p within dir described by e, if it
+// is one.
+func createHardlink(dir, p string, e Entry) error {
+	if e.Hardlink == "" {
+		return nil
+	}
+	target := filepath.Join(dir, filepath.FromSlash(e.Hardlink))
+	return os.Link(target, filepath.Join(dir, filepath.FromSlash(p)))
+}
+
+// createSymlink creates the symbolic link at p within dir described by e, if
+// it is one. Its target is used as given.
+func createSymlink(dir, p string, e Entry) error {
+	if e.Symlink == "" {
+		return nil
+	}
+	return os.Symlink(filepath.FromSlash(e.Symlink), filepath.Join(dir, filepath.FromSlash(p)))
+}
+
+// Build builds tree in a new temporary directory, which is removed once tb
+// and its subtests have finished, and returns the directory's path. It stops
+// tb if the tree cannot be built.
+func Build(tb testing.TB, tree Tree) string {
+	tb.Helper()
+
+	dir, err := os.MkdirTemp("", "fixture-*")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		// Directories that cannot be read or written could not be removed.
+		for p, e := range tree {
+			if isDir(p) && e.Mode != 0 {
+				_ = os.Chmod(filepath.Join(dir, filepath.FromSlash(p)), 0755)
+			}
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			tb.Error(err)
+		}
+	})
+
+	if err := Create(dir, tree); err != nil {
+		tb.Fatal(fmt.Errorf("building fixture: %w", err))
+	}
+	return dir
+}
@@ -0,0 +1,68 @@
+package fixtures
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuild(t *testing.T) {
+	old := 30 * 24 * time.Hour
+	var dir string
+	t.Run("build", func(t *testing.T) {
+		dir = Build(t, Tree{
+			"project/node_modules/index.js": {Data: []byte("test")},
+			"project/node_modules/big.bin":  File(2 * KiB).Aged(old),
+			"project/node_modules/link.bin": Hardlink("project/node_modules/big.bin"),
+			"project/current":               Symlink("node_modules"),
+			"project/empty/":                Dir().Aged(old),
+			"project/script.sh":             {Data: []byte("#!/bin/sh"), Mode: 0755},
+		})
+
+		data, err := os.ReadFile(filepath.Join(dir, "project", "node_modules", "index.js"))
+		assert.NoError(t, err)
+		assert.Equal(t, "test", string(data))
+
+		info, err := os.Stat(filepath.Join(dir, "project", "node_modules", "big.bin"))
+		assert.NoError(t, err)
+		assert.Equal(t, 2*KiB, info.Size())
+		assert.WithinDuration(t, time.Now().Add(-old), info.ModTime(), time.Minute)
+
+		link, err := os.Stat(filepath.Join(dir, "project", "node_modules", "link.bin"))
+		assert.NoError(t, err)
+		assert.True(t, os.SameFile(info, link))
+
+		target, err := os.Readlink(filepath.Join(dir, "project", "current"))
+		assert.NoError(t, err)
+		assert.Equal(t, "node_modules", target)
+
+		info, err = os.Stat(filepath.Join(dir, "project", "empty"))
+		assert.NoError(t, err)
+		assert.True(t, info.IsDir())
+		assert.WithinDuration(t, time.Now().Add(-old), info.ModTime(), time.Minute)
+
+		info, err = os.Stat(filepath.Join(dir, "project", "script.sh"))
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	})
+
+	// The tree is removed once the test that built it has finished.
+	_, err := os.Stat(dir)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestCreateInvalidHardlink(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-create-invalid-hardlink-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	err = Create(tmpDir, Tree{"link": Hardlink("missing")})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,77 @@
+package go_walk
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ListDirStatFS is like ListDirStat but scans the directory root of fsys,
+// such as an embed.FS, a zip.Reader or an fstest.MapFS, instead of the
+// operating system's filesystem. Paths, including those given to WithExclude,
+// are slash-separated and relative to fsys as io/fs expects.
+func ListDirStatFS(fsys fs.FS, root string, opts ...Option) ([]DirectoryInfo, error) {
+	o := newOptions(opts...)
+	o.fsys = fsys
+	return listDirStat(context.Background(), root, o)
+}
+
+// stat returns the metadata of the file at name, following symbolic links.
+func (o *options) stat(name string) (fs.FileInfo, error) {
+	if o.fsys == nil {
+		return os.Stat(name)
+	}
+	return fs.Stat(o.fsys, name)
+}
+
+// readDir returns the entries of the directory at name sorted by name,
+// waiting for a handle to become available under WithMaxOpenFDs. Reads that
+// run out of file descriptors are retried with fewer handles open.
+func (o *options) readDir(name string) ([]fs.DirEntry, error) {
+	entries, err := o.readDirOnce(name)
+	for attempt := 0; tooManyOpenFiles(err) && attempt < fdRetries; attempt++ {
+		o.fds.backOff(attempt)
+		entries, err = o.readDirOnce(name)
+	}
+	return entries, err
+}
+
+// readDirOnce is readDir without retries.
+func (o *options) readDirOnce(name string) ([]fs.DirEntry, error) {
+	o.fds.acquire()
+	defer o.fds.release()
+	if o.fsys == nil {
+		return os.ReadDir(name)
+	}
+	return fs.ReadDir(o.fsys, name)
+}
+
+// join joins path elements with the separator of the filesystem scanned.
+func (o *options) join(elem ...string) string {
+	if o.fsys == nil {
+		return filepath.Join(elem...)
+	}
+	return path.Join(elem...)
+}
+
+// walkDir walks the tree rooted at root like filepath.WalkDir.
+func (o *options) walkDir(root string, fn fs.WalkDirFunc) error {
+	if o.fsys == nil {
+		return filepath.WalkDir(root, fn)
+	}
+	return fs.WalkDir(o.fsys, root, fn)
+}
+
+// deviceOf returns the device that path resides on. The second return value
+// reports whether the filesystem provides device numbers.
+func (o *options) deviceOf(path string) (uint64, bool, error) {
+	info, err := o.stat(path)
+	if err != nil {
+		return 0, false, err
+	}
+
+	st, ok := statOf(info)
+	return st.dev, ok, nil
+}
@@ -0,0 +1,208 @@
+package go_walk
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/akshaybabloo/go-walk/testfs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListDirStatFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"project1/node_modules/package/index.js": {Data: []byte("test")},
+		"project1/node_modules/package/lib.js":   {Data: []byte("test")},
+		"project1/src/main.js":                   {Data: []byte("test")},
+		"project2/node_modules/index.js":         {Data: []byte("test")},
+		"project3/vendor/node_modules/index.js":  {Data: []byte("test")},
+	}
+
+	directories, err := ListDirStatFS(fsys, ".", WithKeywords("node_modules"))
+	assert.NoError(t, err)
+	sort.Slice(directories, func(i, j int) bool {
+		return directories[i].Path < directories[j].Path
+	})
+
+	assert.Len(t, directories, 3)
+	if len(directories) == 3 {
+		assert.Equal(t, "project1/node_modules", directories[0].Path)
+		assert.Equal(t, int64(8), directories[0].Size)
+		assert.Equal(t, 2, directories[0].NumberOfFiles)
+		assert.Equal(t, 2, directories[0].NumberOfSubdirs)
+		assert.Equal(t, "project2/node_modules", directories[1].Path)
+		assert.Equal(t, "project3/vendor/node_modules", directories[2].Path)
+	}
+
+	directories, err = ListDirStatFS(fsys, "project1", WithKeywords("node_modules"))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 1)
+
+	directories, err = ListDirStatFS(fsys, ".", WithKeywords("node_modules"), WithExclude("vendor", "project2"))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 1)
+
+	directories, err = ListDirStatFS(fsys, ".", WithKeywords("node_modules"), WithExclude("project3/vendor"))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 2)
+
+	_, err = ListDirStatFS(fsys, "missing")
+	assert.Error(t, err)
+
+	_, err = ListDirStatFS(fsys, "project1/src/main.js")
+	assert.Error(t, err)
+}
+
+func TestListDirStatFSWithFaults(t *testing.T) {
+	fsys := testfs.New(fstest.MapFS{
+		"project1/node_modules/index.js":         {Data: []byte("test")},
+		"project1/node_modules/private/index.js": {Data: []byte("test")},
+		"project1/node_modules/gone.js":          {Data: []byte("test")},
+		"project2/node_modules/index.js":         {Data: []byte("test")},
+	})
+	fsys.Deny("project1/node_modules/private")
+	fsys.Vanish("project1/node_modules/gone.js")
+
+	directories, err := ListDirStatFS(fsys, ".", WithKeywords("node_modules"), WithSort(ByPath, Ascending))
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.Len(t, directories, 2)
+	if len(directories) == 2 {
+		assert.Equal(t, int64(4), directories[0].Size)
+		assert.True(t, directories[0].Incomplete)
+		assert.Equal(t, 2, directories[0].SkippedEntries)
+		assert.False(t, directories[1].Incomplete)
+	}
+
+	directories, err = ListDirStatFS(fsys, ".", WithKeywords("node_modules"), WithErrorPolicy(Ignore))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 2)
+
+	_, err = ListDirStatFS(fsys, ".", WithKeywords("node_modules"), WithErrorPolicy(FailFast))
+	assert.Len(t, err, 1)
+}
+
+func TestListDirStatFSWithVanishedMatch(t *testing.T) {
+	fsys := testfs.New(fstest.MapFS{
+		"project1/node_modules/index.js": {Data: []byte("test")},
+		"project2/node_modules/index.js": {Data: []byte("test")},
+	})
+	// Removed between being found and being measured
+	fsys.Vanish("project2/node_modules")
+
+	directories, err := ListDirStatFS(fsys, ".", WithKeywords("node_modules"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	if assert.Len(t, directories, 1) {
+		assert.Equal(t, "project1/node_modules", directories[0].Path)
+	}
+
+	directories, err = ListDirStatFS(fsys, ".", WithKeywords("node_modules"), WithErrorPolicy(Ignore))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 1)
+}
+
+// openCountingFS records the most directories of an fs.FS listed at once.
+type openCountingFS struct {
+	fs.FS
+	open, most atomic.Int32
+}
+
+func (f *openCountingFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	n := f.open.Add(1)
+	defer f.open.Add(-1)
+	for {
+		most := f.most.Load()
+		if n <= most || f.most.CompareAndSwap(most, n) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	return fs.ReadDir(f.FS, name)
+}
+
+func TestListDirStatFSWithMaxOpenFDs(t *testing.T) {
+	tree := fstest.MapFS{}
+	for i := 0; i < 32; i++ {
+		tree[fmt.Sprintf("project%d/node_modules/a/b/index.js", i)] = &fstest.MapFile{Data: []byte("test")}
+	}
+
+	fsys := &openCountingFS{FS: tree}
+	directories, err := ListDirStatFS(fsys, ".", WithKeywords("node_modules"), WithWorkers(16))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 32)
+	assert.Greater(t, fsys.most.Load(), int32(2))
+
+	fsys = &openCountingFS{FS: tree}
+	directories, err = ListDirStatFS(fsys, ".", WithKeywords("node_modules"), WithWorkers(16), WithMaxOpenFDs(2))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 32)
+	assert.LessOrEqual(t, fsys.most.Load(), int32(2))
+}
+
+// exhaustedFS fails to list every directory of an fs.FS the first time, as
+// if the process had run out of file descriptors.
+type exhaustedFS struct {
+	fs.FS
+	mu     sync.Mutex
+	listed map[string]bool
+}
+
+func (f *exhaustedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	f.mu.Lock()
+	listed := f.listed[name]
+	f.listed[name] = true
+	f.mu.Unlock()
+	if !listed {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: syscall.EMFILE}
+	}
+	return fs.ReadDir(f.FS, name)
+}
+
+func TestListDirStatFSRetriesWithoutFileDescriptors(t *testing.T) {
+	if !tooManyOpenFiles(syscall.EMFILE) {
+		t.Skip("running out of file descriptors is not recognised on this platform")
+	}
+
+	fsys := &exhaustedFS{FS: fstest.MapFS{
+		"project1/node_modules/a/index.js": {Data: []byte("test")},
+		"project1/node_modules/b/index.js": {Data: []byte("test")},
+		"project2/node_modules/index.js":   {Data: []byte("test")},
+	}, listed: make(map[string]bool)}
+
+	directories, err := ListDirStatFS(fsys, ".", WithKeywords("node_modules"), WithSort(ByPath, Ascending))
+	assert.NoError(t, err)
+	if assert.Len(t, directories, 2) {
+		assert.Equal(t, int64(8), directories[0].Size)
+		assert.False(t, directories[0].Incomplete)
+		assert.Equal(t, int64(4), directories[1].Size)
+	}
+}
+
+func TestListDirStatFSWithTiming(t *testing.T) {
+	fsys := testfs.New(fstest.MapFS{
+		"fast/node_modules/index.js":   {Data: []byte("test")},
+		"slow/node_modules/a/index.js": {Data: []byte("test")},
+	})
+
+	directories, err := ListDirStatFS(fsys, ".", WithKeywords("node_modules"))
+	assert.NoError(t, err)
+	for _, dir := range directories {
+		assert.Zero(t, dir.Elapsed)
+	}
+
+	fsys.SetLatency(5 * time.Millisecond)
+	directories, err = ListDirStatFS(fsys, ".", WithKeywords("node_modules"), WithTiming(), WithSort(ByPath, Ascending))
+	assert.NoError(t, err)
+	if assert.Len(t, directories, 2) {
+		assert.Equal(t, "fast/node_modules", directories[0].Path)
+		assert.Positive(t, directories[0].Elapsed)
+		// Listing the deeper tree takes more calls, each of them delayed.
+		assert.Greater(t, directories[1].Elapsed, directories[0].Elapsed)
+	}
+}
@@ -0,0 +1,232 @@
+package go_walk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GameInstall describes a game installed by a launcher such as Steam or Epic
+// Games.
+type GameInstall struct {
+	Launcher     string `json:"launcher" yaml:"launcher"`           // Launcher that installed the game, "steam" or "epic".
+	ID           string `json:"id,omitempty" yaml:"id,omitempty"`   // Launcher specific identifier, e.g. the Steam app ID.
+	Name         string `json:"name" yaml:"name"`                   // Display name of the game.
+	Path         string `json:"path" yaml:"path"`                   // Install directory of the game.
+	Size         int64  `json:"size" yaml:"size"`                   // Install size in bytes.
+	FromManifest bool   `json:"from_manifest" yaml:"from_manifest"` // Whether Size was read from the launcher's manifest rather than measured.
+}
+
+// AnalyzeSteamLibrary reports the games installed in the Steam library folder
+// library, the directory holding "steamapps", largest first. Sizes are read
+// from the appmanifest_*.acf files where they record one; other games, and
+// directories in steamapps/common without a manifest, are measured. Returns
+// aggregated errors alongside the games found if they occur.
+func AnalyzeSteamLibrary(library string) ([]GameInstall, error) {
+	steamapps := filepath.Join(library, "steamapps")
+	common := filepath.Join(steamapps, "common")
+
+	manifests, err := filepath.Glob(filepath.Join(steamapps, "appmanifest_*.acf"))
+	if err != nil {
+		return nil, err
+	}
+
+	var games []GameInstall
+	var errs ErrorList
+	seen := make(map[string]struct{})
+
+	for _, manifest := range manifests {
+		data, err := os.ReadFile(manifest)
+		if err != nil {
+			errs.add(err)
+			continue
+		}
+
+		fields, err := parseVDF(string(data))
+		if err != nil {
+			errs.add(&WalkError{Path: manifest, Op: "parse", Err: err})
+			continue
+		}
+
+		installDir := fields["installdir"]
+		if installDir == "" {
+			continue
+		}
+
+		game := GameInstall{
+			Launcher: "steam",
+			ID:       fields["appid"],
+			Name:     fields["name"],
+			Path:     filepath.Join(common, installDir),
+		}
+		if size, err := strconv.ParseInt(fields["SizeOnDisk"], 10, 64); err == nil && size > 0 {
+			game.Size = size
+			game.FromManifest = true
+		}
+		seen[game.Path] = struct{}{}
+		games = append(games, game)
+	}
+
+	entries, err := os.ReadDir(common)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		errs.add(err)
+	}
+	for _, entry := range entries {
+		path := filepath.Join(common, entry.Name())
+		if _, exists := seen[path]; exists || !entry.IsDir() {
+			continue
+		}
+		games = append(games, GameInstall{Launcher: "steam", Name: entry.Name(), Path: path})
+	}
+
+	return measureGames(games, &errs), errs.err()
+}
+
+// AnalyzeEpicManifests reports the games described by the Epic Games Launcher
+// manifests (*.item) in manifestDir, largest first. On Windows these live in
+// "%ProgramData%\Epic\EpicGamesLauncher\Data\Manifests". Sizes are read from
+// the manifests where they record one, otherwise the install directory is
+// measured. Returns aggregated errors alongside the games found if they occur.
+func AnalyzeEpicManifests(manifestDir string) ([]GameInstall, error) {
+	manifests, err := filepath.Glob(filepath.Join(manifestDir, "*.item"))
+	if err != nil {
+		return nil, err
+	}
+
+	var games []GameInstall
+	var errs ErrorList
+
+	for _, manifest := range manifests {
+		data, err := os.ReadFile(manifest)
+		if err != nil {
+			errs.add(err)
+			continue
+		}
+
+		var item struct {
+			AppName         string
+			DisplayName     string
+			InstallLocation string
+			InstallSize     int64
+		}
+		if err := json.Unmarshal(data, &item); err != nil {
+			errs.add(&WalkError{Path: manifest, Op: "parse", Err: err})
+			continue
+		}
+		if item.InstallLocation == "" {
+			continue
+		}
+
+		games = append(games, GameInstall{
+			Launcher:     "epic",
+			ID:           item.AppName,
+			Name:         item.DisplayName,
+			Path:         item.InstallLocation,
+			Size:         item.InstallSize,
+			FromManifest: item.InstallSize > 0,
+		})
+	}
+
+	return measureGames(games, &errs), errs.err()
+}
+
+// measureGames measures the install directories of the games whose size is
+// not known from a manifest, dropping those that no longer exist, and sorts
+// the games largest first.
+func measureGames(games []GameInstall, errs *ErrorList) []GameInstall {
+	result := games[:0]
+	for _, game := range games {
+		if !game.FromManifest {
+			dirStat, err := calculateDirStats(context.Background(), game.Path, newOptions())
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				errs.add(err)
+			}
+			game.Size = dirStat.Size
+		}
+		result = append(result, game)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Size != result[j].Size {
+			return result[i].Size > result[j].Size
+		}
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+// parseVDF parses the Valve KeyValues text format used by Steam manifests and
+// returns the key-value pairs of the first block, ignoring nested blocks.
+func parseVDF(data string) (map[string]string, error) {
+	tokens, err := vdfTokens(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// The document is a single named block: "AppState" { ... }.
+	if len(tokens) < 2 || tokens[1] != "{" {
+		return nil, errors.New("missing root block")
+	}
+
+	fields := make(map[string]string)
+	depth := 0
+	for i := 1; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth == 0 {
+				return fields, nil
+			}
+		default:
+			if i+1 >= len(tokens) {
+				return nil, errors.New("unexpected end of input")
+			}
+			if next := tokens[i+1]; next != "{" && next != "}" {
+				if depth == 1 {
+					fields[tokens[i]] = next
+				}
+				i++
+			}
+		}
+	}
+	return nil, errors.New("unterminated block")
+}
+
+// vdfTokens splits data into quoted strings and braces, dropping "//"
+// comments.
+func vdfTokens(data string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '{' || c == '}':
+			tokens = append(tokens, string(c))
+		case c == '"':
+			var sb strings.Builder
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' && i+1 < len(data) {
+					i++
+				}
+				sb.WriteByte(data[i])
+			}
+			if i >= len(data) {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, sb.String())
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+		}
+	}
+	return tokens, nil
+}
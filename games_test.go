@@ -0,0 +1,133 @@
+package go_walk
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akshaybabloo/go-walk/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeSteamLibrary(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-analyze-steam-library-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	steamapps := filepath.Join(tmpDir, "steamapps")
+	portal := filepath.Join(steamapps, "common", "Portal 2")
+	celeste := filepath.Join(steamapps, "common", "Celeste")
+	orphan := filepath.Join(steamapps, "common", "Orphan")
+
+	for _, dir := range []string{portal, celeste, orphan} {
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(t, err)
+	}
+
+	manifests := map[string]string{
+		"appmanifest_620.acf": `"AppState"
+{
+	"appid"		"620"
+	"name"		"Portal 2"
+	"installdir"		"Portal 2"
+	"SizeOnDisk"		"13000000000"
+	"InstalledDepots"
+	{
+		"621"
+		{
+			"size"		"1"
+		}
+	}
+}`,
+		"appmanifest_504230.acf": `"AppState"
+{
+	"appid"		"504230"
+	"name"		"Celeste"
+	"installdir"		"Celeste"
+}`,
+	}
+	for name, content := range manifests {
+		err = os.WriteFile(filepath.Join(steamapps, name), []byte(content), 0644)
+		assert.NoError(t, err)
+	}
+
+	err = os.WriteFile(filepath.Join(celeste, "Celeste.exe"), make([]byte, 300), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(orphan, "data.pak"), make([]byte, 100), 0644)
+	assert.NoError(t, err)
+
+	games, err := AnalyzeSteamLibrary(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, []GameInstall{
+		{Launcher: "steam", ID: "620", Name: "Portal 2", Path: portal, Size: 13000000000, FromManifest: true},
+		{Launcher: "steam", ID: "504230", Name: "Celeste", Path: celeste, Size: 300},
+		{Launcher: "steam", Name: "Orphan", Path: orphan, Size: 100},
+	}, games)
+}
+
+func TestAnalyzeEpicManifests(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-analyze-epic-manifests-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	manifestDir := filepath.Join(tmpDir, "Manifests")
+	fortnite := filepath.Join(tmpDir, "Games", "Fortnite")
+	hades := filepath.Join(tmpDir, "Games", "Hades")
+
+	for _, dir := range []string{manifestDir, fortnite, hades} {
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(t, err)
+	}
+
+	err = os.WriteFile(filepath.Join(hades, "Hades.exe"), make([]byte, 200), 0644)
+	assert.NoError(t, err)
+
+	items := map[string]map[string]any{
+		"fortnite.item": {"AppName": "Fortnite", "DisplayName": "Fortnite", "InstallLocation": fortnite, "InstallSize": 5000},
+		"hades.item":    {"AppName": "Min", "DisplayName": "Hades", "InstallLocation": hades},
+	}
+	for name, item := range items {
+		data, err := json.Marshal(item)
+		assert.NoError(t, err)
+		err = os.WriteFile(filepath.Join(manifestDir, name), data, 0644)
+		assert.NoError(t, err)
+	}
+
+	games, err := AnalyzeEpicManifests(manifestDir)
+	assert.NoError(t, err)
+	assert.Equal(t, []GameInstall{
+		{Launcher: "epic", ID: "Fortnite", Name: "Fortnite", Path: fortnite, Size: 5000, FromManifest: true},
+		{Launcher: "epic", ID: "Min", Name: "Hades", Path: hades, Size: 200},
+	}, games)
+}
+
+func TestMeasureGamesWithUnreadableDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for the superuser")
+	}
+
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"Celeste/Celeste.exe": fixtures.File(300),
+		"Celeste/Saves/":      {Mode: 0200},
+	})
+	celeste := filepath.Join(tmpDir, "Celeste")
+
+	// The game is kept with the size that could be measured, and missing
+	// games are dropped without an error
+	var errs ErrorList
+	games := measureGames([]GameInstall{
+		{Launcher: "steam", Name: "Celeste", Path: celeste},
+		{Launcher: "steam", Name: "Removed", Path: filepath.Join(tmpDir, "Removed")},
+	}, &errs)
+	assert.ErrorIs(t, errs.err(), os.ErrPermission)
+	assert.Equal(t, []GameInstall{{Launcher: "steam", Name: "Celeste", Path: celeste, Size: 300}}, games)
+}
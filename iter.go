@@ -0,0 +1,79 @@
+//go:build go1.23
+
+package go_walk
+
+import (
+	"context"
+	"iter"
+)
+
+// WalkDirs returns an iterator over the directories ListDirStat would
+// return, each paired with a nil error, interleaved with the errors that
+// occur, each paired with an empty DirectoryInfo. Directories are measured
+// while the loop runs, and breaking out of it stops the scan.
+//
+//	for dir, err := range walk.WalkDirs("/", walk.WithKeywords("node_modules")) {
+//		if err != nil {
+//			continue
+//		}
+//		fmt.Println(dir.Path)
+//		break
+//	}
+func WalkDirs(root string, opts ...Option) iter.Seq2[DirectoryInfo, error] {
+	return func(yield func(DirectoryInfo, error) bool) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		dirChan, errChan, err := streamDirStat(ctx, root, newOptions(opts...))
+		if err != nil {
+			yield(DirectoryInfo{}, err)
+			return
+		}
+		// The scan only finishes once both channels have been drained.
+		defer func() {
+			go drain(dirChan, errChan)
+		}()
+
+		for dirChan != nil || errChan != nil {
+			select {
+			case dirStat, ok := <-dirChan:
+				if !ok {
+					dirChan = nil
+					continue
+				}
+				if dirStat.Partial {
+					// ListDirStat does not return progress either.
+					continue
+				}
+				if !yield(dirStat, nil) {
+					return
+				}
+			case e, ok := <-errChan:
+				if !ok {
+					errChan = nil
+					continue
+				}
+				if !yield(DirectoryInfo{}, e) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// drain discards everything delivered on dirChan and errChan until both are
+// closed.
+func drain(dirChan <-chan DirectoryInfo, errChan <-chan error) {
+	for dirChan != nil || errChan != nil {
+		select {
+		case _, ok := <-dirChan:
+			if !ok {
+				dirChan = nil
+			}
+		case _, ok := <-errChan:
+			if !ok {
+				errChan = nil
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+//go:build go1.23
+
+package go_walk
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalkDirs(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-walk-dirs-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	for i := 0; i < 20; i++ {
+		err = os.MkdirAll(filepath.Join(tmpDir, "project"+strconv.Itoa(i), "node_modules"), 0755)
+		assert.NoError(t, err)
+	}
+
+	var count int
+	for dir, err := range WalkDirs(tmpDir, WithKeywords("node_modules")) {
+		assert.NoError(t, err)
+		assert.Equal(t, "node_modules", filepath.Base(dir.Path))
+		count++
+	}
+	assert.Equal(t, 20, count)
+
+	// Breaking out of the loop stops the scan
+	count = 0
+	for _, err := range WalkDirs(tmpDir, WithKeywords("node_modules")) {
+		assert.NoError(t, err)
+		count++
+		break
+	}
+	assert.Equal(t, 1, count)
+
+	for dir, err := range WalkDirs(filepath.Join(tmpDir, "missing")) {
+		assert.Error(t, err)
+		assert.Empty(t, dir.Path)
+	}
+}
+
+func TestWalkDirsWithProgress(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for i := 0; i < 4*progressCheckEvery; i++ {
+		fsys[fmt.Sprintf("node_modules/%d.js", i)] = &fstest.MapFile{Data: []byte("test")}
+	}
+	withFS := func(o *options) { o.fsys = fsys }
+
+	// Only the directories ListDirStat would return, without progress
+	var dirs []DirectoryInfo
+	for dir, err := range WalkDirs(".", withFS, WithKeywords("node_modules"), WithProgress(time.Nanosecond)) {
+		assert.NoError(t, err)
+		dirs = append(dirs, dir)
+	}
+	if assert.Len(t, dirs, 1) {
+		assert.False(t, dirs[0].Partial)
+		assert.Equal(t, 4*progressCheckEvery, dirs[0].NumberOfFiles)
+	}
+}
@@ -0,0 +1,106 @@
+package go_walk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// knownCaches maps the name of a well-known cache to where it is kept,
+// relative to the home directory, on any platform. Paths may contain patterns
+// in the syntax of filepath.Match and may name files as well as directories.
+var knownCaches = map[string][]string{
+	"Chrome cache": {
+		".cache/google-chrome",
+		"Library/Caches/Google/Chrome",
+		"AppData/Local/Google/Chrome/User Data/*/Cache",
+	},
+	"Chromium cache": {".cache/chromium", "Library/Caches/Chromium"},
+	"Firefox cache": {
+		".cache/mozilla/firefox",
+		"Library/Caches/Firefox",
+		"AppData/Local/Mozilla/Firefox/Profiles/*/cache2",
+	},
+	"Safari cache": {"Library/Caches/com.apple.Safari"},
+	"Thumbnail cache": {
+		".cache/thumbnails",
+		".thumbnails",
+		"AppData/Local/Microsoft/Windows/Explorer/thumbcache_*.db",
+	},
+	"pip cache":      {".cache/pip", "Library/Caches/pip", "AppData/Local/pip/cache"},
+	"conda packages": {".conda/pkgs", "miniconda3/pkgs", "anaconda3/pkgs"},
+	"npm cache":      {".npm/_cacache", "AppData/Local/npm-cache"},
+	"Go build cache": {".cache/go-build", "Library/Caches/go-build", "AppData/Local/go-build"},
+	"Docker Desktop VM disk": {
+		"Library/Containers/com.docker.docker/Data/vms/0/data/Docker.raw",
+		".docker/desktop/vms/0/data/Docker.raw",
+		"AppData/Local/Docker/wsl/data/ext4.vhdx",
+		"AppData/Local/Docker/wsl/disk/docker_data.vhdx",
+	},
+}
+
+// KnownCache is a well-known cache found in a home directory.
+type KnownCache struct {
+	Name          string `json:"name" yaml:"name"`                       // What the cache is, e.g. "pip cache".
+	Path          string `json:"path" yaml:"path"`                       // Path of the cache file or directory.
+	Size          int64  `json:"size" yaml:"size"`                       // Size in bytes, recursively for directories.
+	NumberOfFiles int    `json:"number_of_files" yaml:"number_of_files"` // Number of files in the cache.
+}
+
+// FindKnownCaches reports the well-known caches, such as browser caches,
+// thumbnail databases, pip and conda caches or the Docker Desktop VM disk,
+// found in home, largest first. An empty home uses the current user's home
+// directory. Returns aggregated errors alongside the caches found if they
+// occur.
+func FindKnownCaches(home string) ([]KnownCache, error) {
+	if home == "" {
+		var err error
+		home, err = os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var caches []KnownCache
+	var errs ErrorList
+	seen := make(map[string]struct{})
+
+	for name, patterns := range knownCaches {
+		for _, pattern := range patterns {
+			matches, _ := filepath.Glob(filepath.Join(home, filepath.FromSlash(pattern)))
+			for _, path := range matches {
+				if _, exists := seen[path]; exists {
+					continue
+				}
+				seen[path] = struct{}{}
+
+				info, err := os.Stat(path)
+				if err != nil {
+					errs.add(err)
+					continue
+				}
+
+				cache := KnownCache{Name: name, Path: path, Size: info.Size(), NumberOfFiles: 1}
+				if info.IsDir() {
+					dirStat, err := calculateDirStats(context.Background(), path, newOptions())
+					if err != nil {
+						errs.add(err)
+					}
+					cache.Size = dirStat.Size
+					cache.NumberOfFiles = dirStat.NumberOfFiles
+				}
+				caches = append(caches, cache)
+			}
+		}
+	}
+
+	sort.Slice(caches, func(i, j int) bool {
+		if caches[i].Size != caches[j].Size {
+			return caches[i].Size > caches[j].Size
+		}
+		return caches[i].Path < caches[j].Path
+	})
+
+	return caches, errs.err()
+}
@@ -0,0 +1,68 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akshaybabloo/go-walk/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindKnownCaches(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-find-known-caches-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	pip := filepath.Join(tmpDir, ".cache", "pip")
+	firefox := filepath.Join(tmpDir, "AppData", "Local", "Mozilla", "Firefox", "Profiles", "abc.default", "cache2")
+	dockerDisk := filepath.Join(tmpDir, ".docker", "desktop", "vms", "0", "data", "Docker.raw")
+	documents := filepath.Join(tmpDir, "Documents")
+
+	for _, dir := range []string{pip, firefox, filepath.Dir(dockerDisk), documents} {
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(t, err)
+	}
+
+	files := map[string]int{
+		filepath.Join(pip, "wheel-1"):         300,
+		filepath.Join(pip, "wheel-2"):         200,
+		filepath.Join(firefox, "entry"):       100,
+		dockerDisk:                            1000,
+		filepath.Join(documents, "notes.txt"): 5000,
+	}
+	for path, size := range files {
+		err = os.WriteFile(path, make([]byte, size), 0644)
+		assert.NoError(t, err)
+	}
+
+	caches, err := FindKnownCaches(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, []KnownCache{
+		{Name: "Docker Desktop VM disk", Path: dockerDisk, Size: 1000, NumberOfFiles: 1},
+		{Name: "pip cache", Path: pip, Size: 500, NumberOfFiles: 2},
+		{Name: "Firefox cache", Path: firefox, Size: 100, NumberOfFiles: 1},
+	}, caches)
+}
+
+func TestFindKnownCachesWithUnreadableDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for the superuser")
+	}
+
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		".cache/pip/wheel":    fixtures.File(300),
+		".cache/pip/private/": {Mode: 0200},
+	})
+
+	// The cache is listed with the size that could be measured
+	caches, err := FindKnownCaches(tmpDir)
+	assert.ErrorIs(t, err, os.ErrPermission)
+	assert.Equal(t, []KnownCache{
+		{Name: "pip cache", Path: filepath.Join(tmpDir, ".cache", "pip"), Size: 300, NumberOfFiles: 1},
+	}, caches)
+}
@@ -0,0 +1,59 @@
+package go_walk
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalSnapshot is a snapshot kept on the filesystem itself, such as the
+// APFS snapshots Time Machine takes hourly on macOS. It holds on to the space
+// of every file deleted or changed since it was taken, which no directory
+// scan can see.
+type LocalSnapshot struct {
+	Name      string    `json:"name" yaml:"name"`             // Name of the snapshot.
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"` // When the snapshot was taken, zero if its name does not tell.
+}
+
+// timeMachineSnapshotLayout is the time format in the names of Time Machine
+// snapshots, such as "com.apple.TimeMachine.2024-01-02-030405.local".
+const timeMachineSnapshotLayout = "2006-01-02-150405"
+
+// parseLocalSnapshots parses the output of "tmutil listlocalsnapshots",
+// which lists one snapshot per line, preceded by a heading on recent versions
+// of macOS.
+func parseLocalSnapshots(output []byte) []LocalSnapshot {
+	var snapshots []LocalSnapshot
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		name := strings.TrimSpace(scanner.Text())
+		if name == "" || strings.HasSuffix(name, ":") {
+			continue
+		}
+
+		snapshot := LocalSnapshot{Name: name}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, "com.apple.TimeMachine."), ".local")
+		if t, err := time.ParseInLocation(timeMachineSnapshotLayout, stamp, time.Local); err == nil {
+			snapshot.CreatedAt = t
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots
+}
+
+// parsePurgeable returns the purgeable space of a volume from the capacity
+// macOS reports as available for important use, printed in bytes in output,
+// and the space available according to the filesystem itself. The former
+// counts purgeable files as free while the latter does not.
+func parsePurgeable(output []byte, available uint64) (int64, error) {
+	important, err := strconv.ParseUint(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if important <= available {
+		return 0, nil
+	}
+	return int64(important - available), nil
+}
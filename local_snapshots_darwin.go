@@ -0,0 +1,45 @@
+package go_walk
+
+import "os/exec"
+
+// localSnapshotsSupported reports whether localSnapshots can find any on this
+// platform.
+const localSnapshotsSupported = true
+
+// localSnapshots returns the local snapshots of the filesystem mounted at
+// mount, which only APFS volumes have.
+func localSnapshots(mount Mount) ([]LocalSnapshot, error) {
+	if mount.Type != "apfs" {
+		return nil, nil
+	}
+
+	output, err := exec.Command("tmutil", "listlocalsnapshots", mount.Path).Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseLocalSnapshots(output), nil
+}
+
+// importantCapacityScript prints the capacity of the volume holding the path
+// it is given that is available for important use, which is only exposed
+// through Foundation.
+const importantCapacityScript = `ObjC.import("Foundation")
+function run(argv) {
+	var key = $.NSURLVolumeAvailableCapacityForImportantUsageKey
+	var values = $.NSURL.fileURLWithPath(argv[0]).resourceValuesForKeysError([key], null)
+	return values.objectForKey(key).stringValue.js
+}`
+
+// purgeableSpace returns the space on the filesystem mounted at mount that
+// macOS frees when it runs low, which only APFS volumes have.
+func purgeableSpace(mount Mount) (int64, error) {
+	if mount.Type != "apfs" || mount.Total == 0 {
+		return 0, nil
+	}
+
+	output, err := exec.Command("osascript", "-l", "JavaScript", "-e", importantCapacityScript, mount.Path).Output()
+	if err != nil {
+		return 0, err
+	}
+	return parsePurgeable(output, mount.Available)
+}
@@ -0,0 +1,19 @@
+//go:build !darwin
+
+package go_walk
+
+// localSnapshotsSupported reports whether localSnapshots can find any on this
+// platform.
+const localSnapshotsSupported = false
+
+// localSnapshots returns the local snapshots of the filesystem mounted at
+// mount, which are only detected on macOS.
+func localSnapshots(Mount) ([]LocalSnapshot, error) {
+	return nil, nil
+}
+
+// purgeableSpace returns the space on the filesystem mounted at mount that
+// the system frees when it runs low, which is only detected on macOS.
+func purgeableSpace(Mount) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,40 @@
+package go_walk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLocalSnapshots(t *testing.T) {
+	output := []byte(`Snapshots for disk /:
+com.apple.TimeMachine.2024-01-02-030405.local
+com.apple.TimeMachine.2024-01-02-040512.local
+com.apple.os.update-8A1F
+
+`)
+
+	snapshots := parseLocalSnapshots(output)
+	assert.Equal(t, []LocalSnapshot{
+		{Name: "com.apple.TimeMachine.2024-01-02-030405.local", CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)},
+		{Name: "com.apple.TimeMachine.2024-01-02-040512.local", CreatedAt: time.Date(2024, 1, 2, 4, 5, 12, 0, time.Local)},
+		{Name: "com.apple.os.update-8A1F"},
+	}, snapshots)
+
+	assert.Empty(t, parseLocalSnapshots(nil))
+}
+
+func TestParsePurgeable(t *testing.T) {
+	purgeable, err := parsePurgeable([]byte("52613349376\n"), 42613349376)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10000000000), purgeable)
+
+	// Nothing is purgeable if the filesystem reports as much available
+	purgeable, err = parsePurgeable([]byte("1000"), 2000)
+	assert.NoError(t, err)
+	assert.Zero(t, purgeable)
+
+	_, err = parsePurgeable([]byte("undefined"), 0)
+	assert.Error(t, err)
+}
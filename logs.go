@@ -0,0 +1,93 @@
+package go_walk
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// logPatterns are the file name patterns, in the syntax of filepath.Match, of
+// log files and their rotated or compressed copies.
+var logPatterns = []string{"*.log", "*.log.*", "*.gz", "*.bz2", "*.xz", "*.zst"}
+
+// LogDirectory describes a directory whose files are mostly logs.
+type LogDirectory struct {
+	Path     string       `json:"path" yaml:"path"`           // Path of the directory.
+	Size     int64        `json:"size" yaml:"size"`           // Combined size of the files directly in the directory in bytes.
+	LogSize  int64        `json:"log_size" yaml:"log_size"`   // Combined size of the log files among them in bytes.
+	LogFiles int          `json:"log_files" yaml:"log_files"` // Number of log files among them.
+	ByAge    AgeBreakdown `json:"by_age" yaml:"by_age"`       // Size of the log files split by age.
+}
+
+// FindLogDirectories returns the directories below root in which log files
+// (*.log and rotated or compressed copies such as *.log.1 or *.gz) make up at
+// least minShare of the size of the files directly in them, largest logs
+// first. A minShare of zero or less uses one half. Large results with much of
+// ByAge in Older usually point at logs that are not rotated. Returns aggregated
+// errors alongside the directories found if they occur.
+func FindLogDirectories(root string, minShare float64) ([]LogDirectory, error) {
+	if minShare <= 0 {
+		minShare = 0.5
+	}
+
+	now := time.Now()
+	dirs := make(map[string]*LogDirectory)
+	var errs ErrorList
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			errs.add(err)
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			errs.add(err)
+			return nil
+		}
+
+		dirPath := filepath.Dir(path)
+		dir, exists := dirs[dirPath]
+		if !exists {
+			dir = &LogDirectory{Path: dirPath}
+			dirs[dirPath] = dir
+		}
+
+		dir.Size += info.Size()
+		if isLogFile(entry.Name()) {
+			dir.LogSize += info.Size()
+			dir.LogFiles++
+			dir.ByAge.add(info.Size(), now.Sub(info.ModTime()))
+		}
+		return nil
+	})
+	if err != nil {
+		errs.add(err)
+	}
+
+	var result []LogDirectory
+	for _, dir := range dirs {
+		if dir.LogSize > 0 && float64(dir.LogSize) >= minShare*float64(dir.Size) {
+			result = append(result, *dir)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LogSize > result[j].LogSize
+	})
+
+	return result, errs.err()
+}
+
+// isLogFile reports whether name is the name of a log file.
+func isLogFile(name string) bool {
+	for _, pattern := range logPatterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
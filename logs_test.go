@@ -0,0 +1,56 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindLogDirectories(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-find-log-directories-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	appLogs := filepath.Join(tmpDir, "var", "log", "app")
+	nginxLogs := filepath.Join(tmpDir, "var", "log", "nginx")
+	src := filepath.Join(tmpDir, "src")
+
+	for _, dir := range []string{appLogs, nginxLogs, src} {
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(t, err)
+	}
+
+	old := time.Now().Add(-90 * 24 * time.Hour)
+	files := map[string]struct {
+		size    int
+		modTime time.Time
+	}{
+		filepath.Join(appLogs, "app.log"):      {1000, time.Now()},
+		filepath.Join(appLogs, "app.log.1"):    {3000, old},
+		filepath.Join(appLogs, "app.log.2.gz"): {500, old},
+		filepath.Join(nginxLogs, "access.log"): {200, time.Now()},
+		filepath.Join(nginxLogs, "nginx.conf"): {100, time.Now()},
+		filepath.Join(src, "main.go"):          {1000, time.Now()},
+		filepath.Join(src, "debug.log"):        {10, time.Now()},
+	}
+	for path, file := range files {
+		err = os.WriteFile(path, make([]byte, file.size), 0644)
+		assert.NoError(t, err)
+		err = os.Chtimes(path, file.modTime, file.modTime)
+		assert.NoError(t, err)
+	}
+
+	dirs, err := FindLogDirectories(tmpDir, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []LogDirectory{
+		{Path: appLogs, Size: 4500, LogSize: 4500, LogFiles: 3, ByAge: AgeBreakdown{LastDay: 1000, Older: 3500}},
+		{Path: nginxLogs, Size: 300, LogSize: 200, LogFiles: 1, ByAge: AgeBreakdown{LastDay: 200}},
+	}, dirs)
+}
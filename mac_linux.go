@@ -0,0 +1,21 @@
+package go_walk
+
+import (
+	"bytes"
+	"os"
+	"sync"
+)
+
+// macDetection reports whether macEnabled can detect anything on this platform.
+const macDetection = true
+
+// macEnabled reports whether SELinux is enforcing or AppArmor is enabled.
+var macEnabled = sync.OnceValue(func() bool {
+	if enforce, err := os.ReadFile("/sys/fs/selinux/enforce"); err == nil && bytes.HasPrefix(enforce, []byte("1")) {
+		return true
+	}
+	if enabled, err := os.ReadFile("/sys/module/apparmor/parameters/enabled"); err == nil && bytes.HasPrefix(enabled, []byte("Y")) {
+		return true
+	}
+	return false
+})
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package go_walk
+
+// macDetection reports whether macEnabled can detect anything on this platform.
+const macDetection = false
+
+// macEnabled reports whether a supported mandatory access control system is
+// active.
+func macEnabled() bool {
+	return false
+}
@@ -0,0 +1,138 @@
+package go_walk
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Mailbox describes a Maildir mailbox folder.
+type Mailbox struct {
+	Name     string `json:"name" yaml:"name"`         // Folder name, "INBOX" for the root of a Maildir++ tree.
+	Path     string `json:"path" yaml:"path"`         // Path of the folder.
+	Messages int    `json:"messages" yaml:"messages"` // Number of messages in cur and new.
+	Unread   int    `json:"unread" yaml:"unread"`     // Number of messages without the seen flag.
+	Size     int64  `json:"size" yaml:"size"`         // Combined size of the messages in bytes.
+}
+
+// MaildirStats reports the message count and size of every Maildir mailbox
+// folder, a directory with "cur" and "new" subdirectories, within root,
+// sorted by path. Message sizes are taken from the ",S=<size>" field of
+// Maildir++ file names where present, so that only messages without one are
+// stat'ed. Returns aggregated errors alongside the mailboxes found if they
+// occur.
+func MaildirStats(root string) ([]Mailbox, error) {
+	var mailboxes []Mailbox
+	var errs ErrorList
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			errs.add(err)
+			return nil
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+
+		switch entry.Name() {
+		case "cur", "new", "tmp":
+			if path != root {
+				return fs.SkipDir
+			}
+		}
+
+		if !isMaildir(path) {
+			return nil
+		}
+
+		mailbox := Mailbox{Name: mailboxName(root, path), Path: path}
+		for _, sub := range []string{"cur", "new"} {
+			entries, err := os.ReadDir(filepath.Join(path, sub))
+			if err != nil {
+				errs.add(err)
+				continue
+			}
+
+			for _, message := range entries {
+				if message.IsDir() || strings.HasPrefix(message.Name(), ".") {
+					continue
+				}
+
+				size, ok := maildirSize(message.Name())
+				if !ok {
+					info, err := message.Info()
+					if err != nil {
+						errs.add(err)
+						continue
+					}
+					size = info.Size()
+				}
+
+				mailbox.Messages++
+				mailbox.Size += size
+				if sub == "new" || !maildirSeen(message.Name()) {
+					mailbox.Unread++
+				}
+			}
+		}
+		mailboxes = append(mailboxes, mailbox)
+		return nil
+	})
+	if err != nil {
+		errs.add(err)
+	}
+
+	sort.Slice(mailboxes, func(i, j int) bool {
+		return pathLess(mailboxes[i].Path, mailboxes[j].Path)
+	})
+
+	return mailboxes, errs.err()
+}
+
+// isMaildir reports whether path has the "cur" and "new" subdirectories of a
+// Maildir folder.
+func isMaildir(path string) bool {
+	for _, sub := range []string{"cur", "new"} {
+		info, err := os.Stat(filepath.Join(path, sub))
+		if err != nil || !info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// mailboxName returns the name of the mailbox folder at path within root.
+// Maildir++ subfolders such as ".Sent" lose their leading dot.
+func mailboxName(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return "INBOX"
+	}
+	return strings.TrimPrefix(filepath.ToSlash(rel), ".")
+}
+
+// maildirSize returns the size recorded in the ",S=<size>" field of a
+// Maildir message file name.
+func maildirSize(name string) (int64, bool) {
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		name = name[:i]
+	}
+
+	for _, field := range strings.Split(name, ",")[1:] {
+		if value, ok := strings.CutPrefix(field, "S="); ok {
+			size, err := strconv.ParseInt(value, 10, 64)
+			return size, err == nil
+		}
+	}
+	return 0, false
+}
+
+// maildirSeen reports whether the info part ":2,<flags>" of a Maildir message
+// file name carries the seen flag.
+func maildirSeen(name string) bool {
+	i := strings.LastIndex(name, ":2,")
+	return i >= 0 && strings.ContainsRune(name[i+3:], 'S')
+}
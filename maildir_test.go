@@ -0,0 +1,49 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaildirStats(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-maildir-stats-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	sent := filepath.Join(tmpDir, ".Sent")
+	for _, dir := range []string{tmpDir, sent} {
+		for _, sub := range []string{"cur", "new", "tmp"} {
+			err = os.MkdirAll(filepath.Join(dir, sub), 0755)
+			assert.NoError(t, err)
+		}
+	}
+	err = os.MkdirAll(filepath.Join(tmpDir, "notes"), 0755)
+	assert.NoError(t, err)
+
+	files := map[string]int{
+		filepath.Join(tmpDir, "new", "1700000001.M1P1.host,S=1000"):     10,
+		filepath.Join(tmpDir, "cur", "1700000002.M2P1.host,S=2000:2,S"): 10,
+		filepath.Join(tmpDir, "cur", "1700000003.M3P1.host:2,"):         300,
+		filepath.Join(tmpDir, "tmp", "1700000004.M4P1.host"):            5000,
+		filepath.Join(sent, "cur", "1700000005.M5P1.host,S=400:2,RS"):   10,
+		filepath.Join(tmpDir, "notes", "todo.txt"):                      50,
+	}
+	for path, size := range files {
+		err = os.WriteFile(path, make([]byte, size), 0644)
+		assert.NoError(t, err)
+	}
+
+	mailboxes, err := MaildirStats(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, []Mailbox{
+		{Name: "INBOX", Path: tmpDir, Messages: 3, Unread: 2, Size: 3300},
+		{Name: "Sent", Path: sent, Messages: 1, Unread: 0, Size: 400},
+	}, mailboxes)
+}
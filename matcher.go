@@ -0,0 +1,103 @@
+package go_walk
+
+import (
+	"io/fs"
+	"path/filepath"
+	"regexp"
+)
+
+// Matcher decides whether a directory encountered during a walk should be
+// reported. path is the full path of the entry and d is its directory entry.
+type Matcher interface {
+	Match(path string, d fs.DirEntry) bool
+}
+
+// MatcherFunc adapts an ordinary function to the Matcher interface.
+type MatcherFunc func(path string, d fs.DirEntry) bool
+
+// Match calls f(path, d).
+func (f MatcherFunc) Match(path string, d fs.DirEntry) bool {
+	return f(path, d)
+}
+
+// Name returns a Matcher that matches entries whose base name is exactly one
+// of names.
+func Name(names ...string) Matcher {
+	nameSet := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		nameSet[name] = struct{}{}
+	}
+
+	return MatcherFunc(func(_ string, d fs.DirEntry) bool {
+		_, exists := nameSet[d.Name()]
+		return exists
+	})
+}
+
+// Glob returns a Matcher that matches entries whose base name matches any of
+// the shell patterns, using the syntax of filepath.Match. Malformed patterns
+// never match.
+func Glob(patterns ...string) Matcher {
+	return MatcherFunc(func(_ string, d fs.DirEntry) bool {
+		for _, pattern := range patterns {
+			if ok, _ := filepath.Match(pattern, d.Name()); ok {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Regex returns a Matcher that matches entries whose base name matches any of
+// the regular expressions.
+func Regex(expressions ...*regexp.Regexp) Matcher {
+	return MatcherFunc(func(_ string, d fs.DirEntry) bool {
+		for _, re := range expressions {
+			if re.MatchString(d.Name()) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Preset returns a Matcher that matches the directory names of a well-known
+// or registered preset, such as "node" or "python". See Presets for the
+// available names. An unknown preset matches nothing.
+func Preset(name string) Matcher {
+	patterns, _, _ := LookupPreset(name)
+	return Glob(patterns...)
+}
+
+// And returns a Matcher that matches when all of matchers match. With no
+// matchers it matches everything.
+func And(matchers ...Matcher) Matcher {
+	return MatcherFunc(func(path string, d fs.DirEntry) bool {
+		for _, m := range matchers {
+			if !m.Match(path, d) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Or returns a Matcher that matches when any of matchers match. With no
+// matchers it matches nothing.
+func Or(matchers ...Matcher) Matcher {
+	return MatcherFunc(func(path string, d fs.DirEntry) bool {
+		for _, m := range matchers {
+			if m.Match(path, d) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Not returns a Matcher that matches when m does not.
+func Not(m Matcher) Matcher {
+	return MatcherFunc(func(path string, d fs.DirEntry) bool {
+		return !m.Match(path, d)
+	})
+}
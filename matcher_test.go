@@ -0,0 +1,59 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListDirStatMatching(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-list-dir-stat-matching-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	nodeModules := filepath.Join(tmpDir, "project1", "node_modules")
+	pycache := filepath.Join(tmpDir, "project2", "__pycache__")
+	buildCache := filepath.Join(tmpDir, "project2", "build-cache")
+
+	for _, dir := range []string{nodeModules, pycache, buildCache} {
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(t, err)
+	}
+
+	paths := func(directories []DirectoryInfo) []string {
+		var result []string
+		for _, dir := range directories {
+			result = append(result, dir.Path)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name    string
+		matcher Matcher
+		want    []string
+	}{
+		{"name", Name("node_modules"), []string{nodeModules}},
+		{"glob", Glob("*-cache"), []string{buildCache}},
+		{"regex", Regex(regexp.MustCompile(`^__.*__$`)), []string{pycache}},
+		{"preset", Preset("python"), []string{pycache}},
+		{"unknown preset", Preset("does-not-exist"), nil},
+		{"or", Or(Preset("node"), Glob("*-cache")), []string{nodeModules, buildCache}},
+		{"and not", And(Glob("*_*"), Not(Name("__pycache__"))), []string{nodeModules}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			directories, err := ListDirStatMatching(tmpDir, tt.matcher)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, paths(directories))
+		})
+	}
+}
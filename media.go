@@ -0,0 +1,224 @@
+package go_walk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// mediaExtensions holds the lower case extensions of photo and video files.
+var mediaExtensions = map[string]struct{}{
+	".jpg": {}, ".jpeg": {}, ".heic": {}, ".heif": {}, ".png": {}, ".gif": {}, ".webp": {},
+	".tif": {}, ".tiff": {}, ".dng": {}, ".cr2": {}, ".nef": {}, ".arw": {}, ".orf": {}, ".rw2": {},
+	".mp4": {}, ".mov": {}, ".m4v": {}, ".avi": {}, ".mkv": {}, ".3gp": {}, ".mts": {},
+}
+
+// exifHeaderLimit is how much of a file is read when looking for EXIF
+// metadata.
+const exifHeaderLimit = 128 << 10
+
+// YearUsage is the size of the media files taken in one year.
+type YearUsage struct {
+	Year          int   `json:"year" yaml:"year"`                       // Year the files were taken.
+	Size          int64 `json:"size" yaml:"size"`                       // Combined size of the files in bytes.
+	NumberOfFiles int   `json:"number_of_files" yaml:"number_of_files"` // Number of files.
+}
+
+// MediaDirectory describes the photos and videos directly in a directory.
+type MediaDirectory struct {
+	Path          string      `json:"path" yaml:"path"`                       // Path of the directory.
+	Size          int64       `json:"size" yaml:"size"`                       // Combined size of the media files in bytes.
+	NumberOfFiles int         `json:"number_of_files" yaml:"number_of_files"` // Number of media files.
+	ByYear        []YearUsage `json:"by_year" yaml:"by_year"`                 // Size per year, oldest first.
+}
+
+// MediaByYear reports, for every directory below root that holds photos or
+// videos, how much of them was taken in each year. The year comes from the
+// EXIF DateTimeOriginal tag of JPEG and TIFF based files where present and
+// from the modification time otherwise. Directories are sorted by path.
+// Returns aggregated errors alongside the directories found if they occur.
+func MediaByYear(root string) ([]MediaDirectory, error) {
+	dirs := make(map[string]*MediaDirectory)
+	years := make(map[string]map[int]*YearUsage)
+	var errs ErrorList
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			errs.add(err)
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if _, ok := mediaExtensions[strings.ToLower(filepath.Ext(entry.Name()))]; !ok {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			errs.add(err)
+			return nil
+		}
+
+		taken, ok := exifDateTaken(path)
+		if !ok {
+			taken = info.ModTime()
+		}
+
+		dirPath := filepath.Dir(path)
+		dir, exists := dirs[dirPath]
+		if !exists {
+			dir = &MediaDirectory{Path: dirPath}
+			dirs[dirPath] = dir
+			years[dirPath] = make(map[int]*YearUsage)
+		}
+		dir.Size += info.Size()
+		dir.NumberOfFiles++
+
+		usage, exists := years[dirPath][taken.Year()]
+		if !exists {
+			usage = &YearUsage{Year: taken.Year()}
+			years[dirPath][taken.Year()] = usage
+		}
+		usage.Size += info.Size()
+		usage.NumberOfFiles++
+		return nil
+	})
+	if err != nil {
+		errs.add(err)
+	}
+
+	var result []MediaDirectory
+	for dirPath, dir := range dirs {
+		for _, usage := range years[dirPath] {
+			dir.ByYear = append(dir.ByYear, *usage)
+		}
+		sort.Slice(dir.ByYear, func(i, j int) bool {
+			return dir.ByYear[i].Year < dir.ByYear[j].Year
+		})
+		result = append(result, *dir)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return pathLess(result[i].Path, result[j].Path)
+	})
+
+	return result, errs.err()
+}
+
+// exifDateTaken returns the EXIF DateTimeOriginal of the JPEG or TIFF based
+// file at path. The second return value reports whether one was found.
+func exifDateTaken(path string) (time.Time, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, exifHeaderLimit))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	tiff, ok := exifTIFF(data)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	value, ok := tiffDateTimeOriginal(tiff)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	taken, err := time.ParseInLocation("2006:01:02 15:04:05", value, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return taken, true
+}
+
+// exifTIFF returns the TIFF structure holding the EXIF metadata of data, the
+// start of a JPEG or TIFF based file.
+func exifTIFF(data []byte) ([]byte, bool) {
+	if bytes.HasPrefix(data, []byte("II*\x00")) || bytes.HasPrefix(data, []byte("MM\x00*")) {
+		return data, true
+	}
+	if !bytes.HasPrefix(data, []byte{0xFF, 0xD8}) {
+		return nil, false
+	}
+
+	// Walk the JPEG segments up to the first APP1 segment holding EXIF data.
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return nil, false
+		}
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || length < 2 || i+2+length > len(data) {
+			return nil, false
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:], true
+		}
+		i += 2 + length
+	}
+	return nil, false
+}
+
+// tiffDateTimeOriginal returns the DateTimeOriginal tag of the EXIF sub-IFD of
+// tiff.
+func tiffDateTimeOriginal(tiff []byte) (string, bool) {
+	const (
+		tagExifIFD          = 0x8769
+		tagDateTimeOriginal = 0x9003
+	)
+
+	if len(tiff) < 8 {
+		return "", false
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if tiff[0] == 'M' {
+		order = binary.BigEndian
+	}
+
+	entry, ok := tiffEntry(tiff, order, order.Uint32(tiff[4:]), tagExifIFD)
+	if !ok {
+		return "", false
+	}
+	entry, ok = tiffEntry(tiff, order, order.Uint32(entry[8:]), tagDateTimeOriginal)
+	if !ok {
+		return "", false
+	}
+
+	count := order.Uint32(entry[4:])
+	offset := order.Uint32(entry[8:])
+	if count < 19 || uint64(offset)+uint64(count) > uint64(len(tiff)) {
+		return "", false
+	}
+	return string(tiff[offset : offset+19]), true
+}
+
+// tiffEntry returns the 12 byte entry for tag in the IFD at offset of tiff.
+func tiffEntry(tiff []byte, order binary.ByteOrder, offset uint32, tag uint16) ([]byte, bool) {
+	if uint64(offset)+2 > uint64(len(tiff)) {
+		return nil, false
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	start := int(offset) + 2
+	for i := 0; i < count; i++ {
+		p := start + i*12
+		if p+12 > len(tiff) {
+			return nil, false
+		}
+		if order.Uint16(tiff[p:]) == tag {
+			return tiff[p : p+12], true
+		}
+	}
+	return nil, false
+}
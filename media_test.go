@@ -0,0 +1,88 @@
+package go_walk
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testJPEG returns a minimal JPEG whose EXIF DateTimeOriginal is taken.
+func testJPEG(taken string) []byte {
+	value := append([]byte(taken), 0)
+
+	// TIFF header, IFD0 with a pointer to the EXIF IFD, EXIF IFD with
+	// DateTimeOriginal, followed by the string value.
+	tiff := []byte("II*\x00")
+	tiff = binary.LittleEndian.AppendUint32(tiff, 8)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x8769)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 4)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 26)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x9003)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+	tiff = binary.LittleEndian.AppendUint32(tiff, uint32(len(value)))
+	tiff = binary.LittleEndian.AppendUint32(tiff, 44)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+	tiff = append(tiff, value...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	jpeg = binary.BigEndian.AppendUint16(jpeg, uint16(len(segment)+2))
+	jpeg = append(jpeg, segment...)
+	return append(jpeg, 0xFF, 0xD9)
+}
+
+func TestMediaByYear(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-media-by-year-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	photos := filepath.Join(tmpDir, "Photos")
+	videos := filepath.Join(tmpDir, "Videos")
+
+	for _, dir := range []string{photos, videos} {
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(t, err)
+	}
+
+	jpeg := testJPEG("2015:06:01 12:00:00")
+	mtime2019 := time.Date(2019, 3, 1, 0, 0, 0, 0, time.Local)
+	files := map[string]struct {
+		data    []byte
+		modTime time.Time
+	}{
+		filepath.Join(photos, "IMG_0001.JPG"): {jpeg, mtime2019},
+		filepath.Join(photos, "IMG_0002.png"): {make([]byte, 100), mtime2019},
+		filepath.Join(photos, "notes.txt"):    {make([]byte, 1000), mtime2019},
+		filepath.Join(videos, "clip.mp4"):     {make([]byte, 500), time.Date(2021, 1, 1, 12, 0, 0, 0, time.Local)},
+	}
+	for path, file := range files {
+		err = os.WriteFile(path, file.data, 0644)
+		assert.NoError(t, err)
+		err = os.Chtimes(path, file.modTime, file.modTime)
+		assert.NoError(t, err)
+	}
+
+	dirs, err := MediaByYear(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, []MediaDirectory{
+		{Path: photos, Size: int64(len(jpeg)) + 100, NumberOfFiles: 2, ByYear: []YearUsage{
+			{Year: 2015, Size: int64(len(jpeg)), NumberOfFiles: 1},
+			{Year: 2019, Size: 100, NumberOfFiles: 1},
+		}},
+		{Path: videos, Size: 500, NumberOfFiles: 1, ByYear: []YearUsage{
+			{Year: 2021, Size: 500, NumberOfFiles: 1},
+		}},
+	}, dirs)
+}
@@ -0,0 +1,41 @@
+package go_walk
+
+import (
+	"io/fs"
+	"log/slog"
+	"time"
+)
+
+// Middleware wraps the function a scan calls for every entry it visits while
+// looking for directories, like HTTP middleware wraps a handler. It can log,
+// time or sample visits, or filter them by returning fs.SkipDir instead of
+// calling next.
+type Middleware func(next fs.WalkDirFunc) fs.WalkDirFunc
+
+// chain wraps visit in middlewares, the first of which is outermost.
+func chain(visit fs.WalkDirFunc, middlewares []Middleware) fs.WalkDirFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		visit = middlewares[i](visit)
+	}
+	return visit
+}
+
+// LogVisits returns a Middleware that logs every directory visited, with the
+// time spent on it, at debug level and every error at warning level.
+func LogVisits(logger *slog.Logger) Middleware {
+	return func(next fs.WalkDirFunc) fs.WalkDirFunc {
+		return func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				logger.Warn("visit failed", "path", path, "error", err)
+				return next(path, d, err)
+			}
+
+			start := time.Now()
+			result := next(path, d, nil)
+			if d.IsDir() {
+				logger.Debug("visited directory", "path", path, "duration", time.Since(start))
+			}
+			return result
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package go_walk
+
+import (
+	"bytes"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-with-middleware-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	for _, dir := range []string{"project1/node_modules", "private/node_modules"} {
+		err = os.MkdirAll(filepath.Join(tmpDir, dir), 0755)
+		assert.NoError(t, err)
+	}
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next fs.WalkDirFunc) fs.WalkDirFunc {
+			return func(path string, d fs.DirEntry, err error) error {
+				if path == tmpDir {
+					order = append(order, name)
+				}
+				return next(path, d, err)
+			}
+		}
+	}
+	skipPrivate := func(next fs.WalkDirFunc) fs.WalkDirFunc {
+		return func(path string, d fs.DirEntry, err error) error {
+			if err == nil && d.IsDir() && d.Name() == "private" {
+				return fs.SkipDir
+			}
+			return next(path, d, err)
+		}
+	}
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	directories, err := ListDirStat(tmpDir,
+		WithKeywords("node_modules"),
+		WithMiddleware(trace("outer"), trace("inner"), skipPrivate, LogVisits(logger)),
+	)
+	assert.NoError(t, err)
+	assert.Len(t, directories, 1)
+	assert.Equal(t, filepath.Join(tmpDir, "project1", "node_modules"), directories[0].Path)
+	assert.Equal(t, []string{"outer", "inner"}, order)
+	assert.Equal(t, 3, strings.Count(logs.String(), "visited directory"))
+}
+
+// denying returns a Middleware that treats the directories called name as
+// unreadable, for testing error policies without relying on permissions,
+// which are not enforced for the superuser.
+func denying(name string) Middleware {
+	return func(next fs.WalkDirFunc) fs.WalkDirFunc {
+		return func(path string, d fs.DirEntry, err error) error {
+			if err == nil && d.IsDir() && filepath.Base(path) == name {
+				_ = next(path, d, &fs.PathError{Op: "open", Path: path, Err: fs.ErrPermission})
+				return fs.SkipDir
+			}
+			return next(path, d, err)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !linux && !darwin && !freebsd && !openbsd && !netbsd && !dragonfly
+
+package go_walk
+
+import "os"
+
+// mmapSupported reports whether mapFile maps files into memory on this
+// platform.
+const mmapSupported = false
+
+// mapFile reads the file at path into memory, on platforms where it is not
+// mapped. The returned function releases it.
+func mapFile(path string) ([]byte, func() error, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
@@ -0,0 +1,37 @@
+//go:build linux || darwin || freebsd || openbsd || netbsd || dragonfly
+
+package go_walk
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapSupported reports whether mapFile maps files into memory on this
+// platform.
+const mmapSupported = true
+
+// mapFile maps the file at path into memory read-only. The returned function
+// unmaps it.
+func mapFile(path string) ([]byte, func() error, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.Size() == 0 {
+		return nil, func() error { return nil }, nil
+	}
+
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
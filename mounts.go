@@ -0,0 +1,238 @@
+package go_walk
+
+import (
+	"os"
+	"sort"
+	"sync"
+)
+
+// remoteFilesystems lists the filesystem types that are backed by network
+// storage.
+var remoteFilesystems = map[string]struct{}{
+	"nfs": {}, "nfs4": {}, "cifs": {}, "smbfs": {}, "smb3": {}, "afpfs": {},
+	"fuse.sshfs": {}, "9p": {}, "ceph": {}, "glusterfs": {}, "davfs": {}, "webdav": {},
+}
+
+// pseudoFilesystems lists the filesystem types that are not backed by any
+// storage and are never worth scanning.
+var pseudoFilesystems = map[string]struct{}{
+	"proc": {}, "sysfs": {}, "devtmpfs": {}, "devpts": {}, "tmpfs": {}, "ramfs": {},
+	"cgroup": {}, "cgroup2": {}, "securityfs": {}, "debugfs": {}, "tracefs": {},
+	"pstore": {}, "bpf": {}, "mqueue": {}, "hugetlbfs": {}, "configfs": {},
+	"fusectl": {}, "binfmt_misc": {}, "nsfs": {}, "efivarfs": {}, "autofs": {},
+	"rpc_pipefs": {}, "squashfs": {}, "devfs": {},
+}
+
+// Mount describes a mounted filesystem.
+type Mount struct {
+	Device    string `json:"device" yaml:"device"`       // Device or source the filesystem is mounted from.
+	Path      string `json:"path" yaml:"path"`           // Path the filesystem is mounted at.
+	Type      string `json:"type" yaml:"type"`           // Filesystem type, e.g. "ext4", "apfs" or "NTFS".
+	Remote    bool   `json:"remote" yaml:"remote"`       // Whether the filesystem is backed by network storage.
+	Total     uint64 `json:"total" yaml:"total"`         // Total capacity of the filesystem in bytes.
+	Free      uint64 `json:"free" yaml:"free"`           // Free space on the filesystem in bytes.
+	Available uint64 `json:"available" yaml:"available"` // Free space available to unprivileged users in bytes.
+}
+
+// ListMounts returns the filesystems mounted on the system. The capacity of a
+// filesystem that cannot be queried is left as zero. On unsupported platforms
+// errors.ErrUnsupported is returned.
+func ListMounts() ([]Mount, error) {
+	mounts, err := listMounts()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range mounts {
+		if _, exists := remoteFilesystems[mounts[i].Type]; exists {
+			mounts[i].Remote = true
+		}
+
+		if mounts[i].Total != 0 {
+			continue
+		}
+
+		st, err := statFS(mounts[i].Path)
+		if err != nil {
+			continue
+		}
+		mounts[i].Total = st.total
+		mounts[i].Free = st.free
+		mounts[i].Available = st.available
+	}
+
+	return mounts, nil
+}
+
+// MountReport holds the results of scanning a single filesystem.
+type MountReport struct {
+	Mount       Mount           `json:"mount" yaml:"mount"`                                 // The filesystem that was scanned.
+	Size        int64           `json:"size" yaml:"size"`                                   // Combined size of the files on the filesystem in bytes.
+	Directories []DirectoryInfo `json:"directories,omitempty" yaml:"directories,omitempty"` // Top-level directories of the filesystem.
+
+	// Unaccounted is the space in use on the filesystem that the scan did
+	// not find in any directory, held by local snapshots, purgeable files,
+	// shadow copies, filesystem metadata or directories that could not be
+	// read.
+	Unaccounted int64 `json:"unaccounted" yaml:"unaccounted"`
+
+	// LocalSnapshots lists the snapshots kept on the filesystem, such as Time
+	// Machine's local snapshots on macOS, a common cause of Unaccounted space.
+	LocalSnapshots []LocalSnapshot `json:"local_snapshots,omitempty" yaml:"local_snapshots,omitempty"`
+
+	// Purgeable is the space macOS counts as in use but frees when it runs
+	// low, held by local snapshots and caches among others. It is only
+	// detected on macOS.
+	Purgeable int64 `json:"purgeable,omitempty" yaml:"purgeable,omitempty"`
+
+	// ShadowStorage is the space used by Volume Shadow Copy Service shadow
+	// copies, such as System Restore points, on Windows. It is only queried
+	// with administrative privileges.
+	ShadowStorage int64 `json:"shadow_storage,omitempty" yaml:"shadow_storage,omitempty"`
+}
+
+// MachineReport combines the scans of every filesystem on the machine.
+type MachineReport struct {
+	Mounts        []MountReport `json:"mounts" yaml:"mounts"`                                     // Scanned filesystems, sorted by path.
+	TotalSize     int64         `json:"total_size" yaml:"total_size"`                             // Combined size of all scanned filesystems in bytes.
+	Unaccounted   int64         `json:"unaccounted" yaml:"unaccounted"`                           // Combined space in use that the scans did not find, see MountReport.
+	Purgeable     int64         `json:"purgeable,omitempty" yaml:"purgeable,omitempty"`           // Combined purgeable space on macOS, see MountReport.
+	ShadowStorage int64         `json:"shadow_storage,omitempty" yaml:"shadow_storage,omitempty"` // Combined space used by shadow copies on Windows, see MountReport.
+	Access        AccessReport  `json:"access" yaml:"access"`                                     // Parts of the machine that could not be read.
+}
+
+// ScanAllMounts scans every local disk of the machine in parallel and
+// combines the results into one report. The filesystems share the workers set
+// by WithWorkers, so that no more directories are measured at once than in a
+// scan of a single one. Each filesystem is scanned from its
+// mount point without crossing into other filesystems. Pseudo filesystems such
+// as proc or tmpfs are never scanned and network filesystems are only scanned
+// with WithRemoteMounts. Returns aggregated errors alongside the partial
+// report if they occur.
+func ScanAllMounts(opts ...Option) (MachineReport, error) {
+	o := newOptions(opts...)
+	o.oneFilesystem = true
+
+	mounts, err := ListMounts()
+	if err != nil {
+		return MachineReport{}, err
+	}
+
+	var report MachineReport
+	var mu sync.Mutex
+	var errs ErrorList
+	seen := make(map[string]struct{})
+
+	// Filesystems are scanned concurrently, their directories measured by
+	// the same workers.
+	sem := make(chan struct{}, o.workerCount())
+	wg := &sync.WaitGroup{}
+	for _, mount := range mounts {
+		if !shouldScanMount(mount, o) {
+			continue
+		}
+
+		// The same filesystem can be mounted several times, e.g. with bind
+		// mounts, but only needs to be scanned once.
+		key, err := filesystemKey(mount.Path)
+		if err != nil {
+			continue
+		}
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		wg.Add(1)
+		go func(mount Mount) {
+			defer wg.Done()
+			mountReport, err := scanMount(mount, o, sem)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs.add(err)
+			}
+			report.Mounts = append(report.Mounts, mountReport)
+			report.TotalSize += mountReport.Size
+			report.Unaccounted += mountReport.Unaccounted
+			report.Purgeable += mountReport.Purgeable
+			report.ShadowStorage += mountReport.ShadowStorage
+		}(mount)
+	}
+	wg.Wait()
+
+	sort.Slice(report.Mounts, func(i, j int) bool {
+		return report.Mounts[i].Mount.Path < report.Mounts[j].Mount.Path
+	})
+
+	report.Access = CheckAccess(errs.err())
+
+	return report, errs.err()
+}
+
+// shouldScanMount reports whether ScanAllMounts should scan mount according
+// to o.
+func shouldScanMount(mount Mount, o *options) bool {
+	if _, exists := pseudoFilesystems[mount.Type]; exists {
+		return false
+	}
+	if mount.Remote && !o.remoteMounts {
+		return false
+	}
+	return true
+}
+
+// scanMount scans the filesystem mounted at mount.Path according to o,
+// measuring as many directories at once as sem holds.
+func scanMount(mount Mount, o *options, sem chan struct{}) (MountReport, error) {
+	report := MountReport{Mount: mount}
+
+	sem <- struct{}{}
+	entries, err := os.ReadDir(mount.Path)
+	<-sem
+	if err != nil {
+		return report, err
+	}
+
+	var onDisk int64
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			report.Size += info.Size()
+			onDisk += allocatedSize(info, 0)
+		}
+	}
+
+	var errs ErrorList
+	report.Directories, err = shallowDirStat(mount.Path, o, sem)
+	if err != nil {
+		errs.add(err)
+	}
+	for _, dir := range report.Directories {
+		report.Size += dir.Size
+		onDisk += dir.SizeOnDisk
+	}
+
+	if used := int64(mount.Total - mount.Free); mount.Total > 0 && used > onDisk {
+		report.Unaccounted = used - onDisk
+	}
+
+	report.LocalSnapshots, err = localSnapshots(mount)
+	if err != nil {
+		errs.add(err)
+	}
+
+	report.Purgeable, err = purgeableSpace(mount)
+	if err != nil {
+		errs.add(err)
+	}
+
+	report.ShadowStorage, err = shadowStorage(mount)
+	if err != nil {
+		errs.add(err)
+	}
+
+	return report, errs.err()
+}
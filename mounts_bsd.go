@@ -0,0 +1,52 @@
+//go:build darwin || freebsd
+
+package go_walk
+
+import "syscall"
+
+// mntNoWait asks getfsstat(2) to return cached information rather than
+// querying every filesystem, which may block on unresponsive network mounts.
+const mntNoWait = 2
+
+// mountsSupported reports whether listMounts works on this platform.
+const mountsSupported = true
+
+// listMounts returns the filesystems reported by getfsstat(2).
+func listMounts() ([]Mount, error) {
+	n, err := syscall.Getfsstat(nil, mntNoWait)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]syscall.Statfs_t, n)
+	n, err = syscall.Getfsstat(buf, mntNoWait)
+	if err != nil {
+		return nil, err
+	}
+
+	mounts := make([]Mount, 0, n)
+	for _, st := range buf[:n] {
+		mounts = append(mounts, Mount{
+			Device:    int8String(st.Mntfromname[:]),
+			Path:      int8String(st.Mntonname[:]),
+			Type:      int8String(st.Fstypename[:]),
+			Total:     uint64(st.Blocks) * uint64(st.Bsize),
+			Free:      uint64(st.Bfree) * uint64(st.Bsize),
+			Available: uint64(st.Bavail) * uint64(st.Bsize),
+		})
+	}
+
+	return mounts, nil
+}
+
+// int8String converts a NUL terminated C string to a string.
+func int8String(chars []int8) string {
+	b := make([]byte, 0, len(chars))
+	for _, c := range chars {
+		if c == 0 {
+			break
+		}
+		b = append(b, byte(c))
+	}
+	return string(b)
+}
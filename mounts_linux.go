@@ -0,0 +1,65 @@
+package go_walk
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// mountsSupported reports whether listMounts works on this platform.
+const mountsSupported = true
+
+// listMounts returns the filesystems listed in /proc/mounts.
+func listMounts() ([]Mount, error) {
+	file, err := os.Open("/proc/mounts")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseMounts(file)
+}
+
+// parseMounts parses a mount table in the format of /proc/mounts.
+func parseMounts(r io.Reader) ([]Mount, error) {
+	var mounts []Mount
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+
+		mounts = append(mounts, Mount{
+			Device: unescapeMountField(fields[0]),
+			Path:   unescapeMountField(fields[1]),
+			Type:   fields[2],
+		})
+	}
+
+	return mounts, scanner.Err()
+}
+
+// unescapeMountField decodes the octal escapes, such as "\040" for a space,
+// used in the fields of /proc/mounts.
+func unescapeMountField(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+4 <= len(field) {
+			if c, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(field[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,23 @@
+package go_walk
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMounts(t *testing.T) {
+	table := `/dev/sda1 / ext4 rw,relatime 0 0
+proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+//server/share /mnt/my\040share cifs rw 0 0
+`
+
+	mounts, err := parseMounts(strings.NewReader(table))
+	assert.NoError(t, err)
+	assert.Equal(t, []Mount{
+		{Device: "/dev/sda1", Path: "/", Type: "ext4"},
+		{Device: "proc", Path: "/proc", Type: "proc"},
+		{Device: "//server/share", Path: "/mnt/my share", Type: "cifs"},
+	}, mounts)
+}
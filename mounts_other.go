@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package go_walk
+
+import "errors"
+
+// mountsSupported reports whether listMounts works on this platform.
+const mountsSupported = false
+
+// listMounts returns the mounted filesystems.
+func listMounts() ([]Mount, error) {
+	return nil, errors.ErrUnsupported
+}
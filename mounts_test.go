@@ -0,0 +1,77 @@
+package go_walk
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListMounts(t *testing.T) {
+	mounts, err := ListMounts()
+	if errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("listing mounts is not supported on this platform")
+	}
+	assert.NoError(t, err)
+	assert.NotEmpty(t, mounts)
+
+	for _, mount := range mounts {
+		assert.NotEmpty(t, mount.Path)
+		assert.NotEmpty(t, mount.Type)
+	}
+}
+
+func TestShouldScanMount(t *testing.T) {
+	tests := []struct {
+		name  string
+		mount Mount
+		opts  []Option
+		want  bool
+	}{
+		{"local disk", Mount{Path: "/", Type: "ext4"}, nil, true},
+		{"pseudo filesystem", Mount{Path: "/proc", Type: "proc"}, nil, false},
+		{"pseudo filesystem with remote mounts", Mount{Path: "/proc", Type: "proc"}, []Option{WithRemoteMounts()}, false},
+		{"remote filesystem", Mount{Path: "/mnt/nas", Type: "nfs", Remote: true}, nil, false},
+		{"remote filesystem with remote mounts", Mount{Path: "/mnt/nas", Type: "nfs", Remote: true}, []Option{WithRemoteMounts()}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, shouldScanMount(tt.mount, newOptions(tt.opts...)))
+		})
+	}
+}
+
+func TestShallowDirStatSharesWorkers(t *testing.T) {
+	tree := fstest.MapFS{}
+	for _, mount := range []string{"disk1", "disk2", "disk3"} {
+		for i := 0; i < 8; i++ {
+			tree[fmt.Sprintf("%s/dir%d/a/index.js", mount, i)] = &fstest.MapFile{Data: []byte("test")}
+		}
+	}
+
+	// Filesystems scanned at once, as by ScanAllMounts, stay within the
+	// workers of a single scan between them, however they are limited
+	for _, opts := range [][]Option{{WithWorkers(2)}, {WithWorkers(16), WithMaxCPU(2)}} {
+		fsys := &openCountingFS{FS: tree}
+		o := newOptions(opts...)
+		o.fsys = fsys
+
+		sem := make(chan struct{}, o.workerCount())
+		var wg sync.WaitGroup
+		for _, mount := range []string{"disk1", "disk2", "disk3"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				directories, err := shallowDirStat(mount, o, sem)
+				assert.NoError(t, err)
+				assert.Len(t, directories, 8)
+			}()
+		}
+		wg.Wait()
+		assert.LessOrEqual(t, fsys.most.Load(), int32(2))
+	}
+}
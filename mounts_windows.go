@@ -0,0 +1,76 @@
+package go_walk
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	procGetLogicalDrives      = kernel32.NewProc("GetLogicalDrives")
+	procGetDriveTypeW         = kernel32.NewProc("GetDriveTypeW")
+	procGetVolumeInformationW = kernel32.NewProc("GetVolumeInformationW")
+)
+
+// driveRemote is the drive type GetDriveTypeW reports for network drives.
+const driveRemote = 4
+
+// mountsSupported reports whether listMounts works on this platform.
+const mountsSupported = true
+
+// listMounts returns the drives reported by GetLogicalDrives.
+func listMounts() ([]Mount, error) {
+	r, _, e := procGetLogicalDrives.Call()
+	if r == 0 {
+		return nil, e
+	}
+
+	var mounts []Mount
+	for i := 0; i < 26; i++ {
+		if r&(1<<uint(i)) == 0 {
+			continue
+		}
+
+		root := string(rune('A'+i)) + `:\`
+		mounts = append(mounts, Mount{
+			Device: root[:2],
+			Path:   root,
+			Type:   volumeFilesystem(root),
+			Remote: driveType(root) == driveRemote,
+		})
+	}
+
+	return mounts, nil
+}
+
+// volumeFilesystem returns the filesystem name of the volume at root, or an
+// empty string if it cannot be determined, e.g. for an empty optical drive.
+func volumeFilesystem(root string) string {
+	rootPtr, err := syscall.UTF16PtrFromString(root)
+	if err != nil {
+		return ""
+	}
+
+	var name [syscall.MAX_PATH + 1]uint16
+	r, _, _ := procGetVolumeInformationW.Call(
+		uintptr(unsafe.Pointer(rootPtr)),
+		0, 0, 0, 0, 0,
+		uintptr(unsafe.Pointer(&name[0])),
+		uintptr(len(name)),
+	)
+	if r == 0 {
+		return ""
+	}
+	return syscall.UTF16ToString(name[:])
+}
+
+// driveType returns the type of the drive at root as reported by
+// GetDriveTypeW.
+func driveType(root string) uintptr {
+	rootPtr, err := syscall.UTF16PtrFromString(root)
+	if err != nil {
+		return 0
+	}
+
+	r, _, _ := procGetDriveTypeW.Call(uintptr(unsafe.Pointer(rootPtr)))
+	return r
+}
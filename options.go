@@ -0,0 +1,418 @@
+package go_walk
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Option configures a scan.
+type Option func(*options)
+
+// options holds the configuration of a scan.
+type options struct {
+	keywords       []string         // Names of the directories to report, all if empty.
+	matchMode      MatchMode        // How keywords are compared with directory names.
+	exclude        []string         // Names or paths of directories not to descend into.
+	annotators     []Annotator      // Enrich every directory before it is delivered.
+	middlewares    []Middleware     // Wrap the visitor looking for directories.
+	matcher        Matcher          // Decides which directories to report, overriding keywords.
+	files          Matcher          // Decides which files to measure, all if nil.
+	fsys           fs.FS            // Filesystem to scan, the operating system's if nil.
+	maxDepth       int              // Deepest level below the root to report directories at, unlimited if zero.
+	minSize        int64            // Smallest size of the directories to report, any if zero.
+	olderThan      time.Duration    // How long ago the directories to report must last have been modified, any if zero.
+	modifiedAfter  time.Time        // When the directories to report must last have been modified after, any time if zero.
+	modifiedBefore time.Time        // When the directories to report must last have been modified before, any time if zero.
+	progress       time.Duration    // How often to deliver partial results, never if zero.
+	maxStaleness   time.Duration    // Oldest cached result a Cache may return, any if zero.
+	debounce       time.Duration    // How long a directory must stop changing before a PollWatcher reports it, not at all if zero.
+	changeInterval time.Duration    // Least time between two changes of a directory a PollWatcher reports, none if zero.
+	workers        int              // Number of directories measured concurrently, defaultWorkers if zero.
+	scheduling     SchedulingPolicy // Order in which matched directories are measured.
+	maxOpenFDs     int              // Most directory handles open at once, unlimited if zero.
+	maxCPU         int              // Most directories measured at once whatever workers is, unlimited if zero.
+	maxMemory      int64            // Memory the workers and results may take in bytes, unlimited if zero.
+	fds            *fdLimiter       // Bounds the directory handles open at once.
+	symlinks       SymlinkPolicy    // What to do with symbolic links.
+	follow         []string         // Names or paths of symbolic links to follow whatever symlinks is.
+	oneFilesystem  bool             // Do not descend into directories on other filesystems.
+	dedupe         bool             // Count files with several hard links once.
+	fileSizes      bool             // Find the largest file of every directory and the average size of its files.
+	extensions     bool             // Break the files of every directory down by extension.
+	timing         bool             // Record how long measuring every directory took.
+	errorPolicy    ErrorPolicy      // What to do with entries that cannot be read.
+	sorted         bool             // Sort the results of ListDirStat by sortKey in sortOrder.
+	sortKey        SortKey          // What to sort the results by.
+	sortOrder      SortOrder        // Which direction to sort the results in.
+	deterministic  bool             // Return results in path order unless sorted otherwise.
+	remoteMounts   bool             // Include network filesystems when scanning all mounts.
+}
+
+// newOptions returns the configuration resulting from applying opts to the
+// defaults.
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	o.fds = newFDLimiter(0)
+	if o.maxOpenFDs > 0 {
+		// The walker looking for directories holds a handle of its own.
+		o.fds = newFDLimiter(max(o.maxOpenFDs-1, 1))
+	}
+	return o
+}
+
+// MatchMode decides how keywords are compared with directory names.
+type MatchMode int
+
+const (
+	// MatchExact matches directories whose name equals a keyword.
+	MatchExact MatchMode = iota
+	// MatchGlob treats keywords as shell patterns in the syntax of
+	// filepath.Match, such as "*.tmp".
+	MatchGlob
+	// MatchRegex treats keywords as regular expressions that must match the
+	// whole directory name.
+	MatchRegex
+)
+
+// matches returns the Matcher deciding which directories to report, nil
+// meaning all of them.
+func (o *options) matches() (Matcher, error) {
+	if o.matcher != nil {
+		return o.matcher, nil
+	}
+	if len(o.keywords) == 0 {
+		return nil, nil
+	}
+
+	keywords := expandAliases(o.keywords)
+	switch o.matchMode {
+	case MatchGlob:
+		for _, keyword := range keywords {
+			if _, err := filepath.Match(keyword, ""); err != nil {
+				return nil, fmt.Errorf("invalid glob keyword %q: %w", keyword, err)
+			}
+		}
+		return Glob(keywords...), nil
+	case MatchRegex:
+		expressions := make([]*regexp.Regexp, 0, len(keywords))
+		for _, keyword := range keywords {
+			re, err := regexp.Compile(`^(?:` + keyword + `)$`)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex keyword %q: %w", keyword, err)
+			}
+			expressions = append(expressions, re)
+		}
+		return Regex(expressions...), nil
+	default:
+		return Name(keywords...), nil
+	}
+}
+
+// WithKeywords limits a scan to directories, or files for ListFileStat, whose
+// name is one of keywords. Aliases registered with RegisterAlias are expanded.
+// Without it all of them are reported.
+func WithKeywords(keywords ...string) Option {
+	return func(o *options) {
+		o.keywords = append(o.keywords, keywords...)
+	}
+}
+
+// workerCount returns the number of directories to measure concurrently,
+// within the limits of WithMaxCPU and WithMaxMemory.
+func (o *options) workerCount() int {
+	n := defaultWorkers
+	if o.workers > 0 {
+		n = o.workers
+	}
+	if o.maxCPU > 0 {
+		n = min(n, o.maxCPU)
+	}
+	if o.maxMemory > 0 {
+		n = min(n, max(int(o.maxMemory/workerMemory), 1))
+	}
+	return n
+}
+
+// excluded reports whether the directory at path must not be descended into.
+func (o *options) excluded(path string) bool {
+	return o.matchesPath(o.exclude, path)
+}
+
+// followed reports whether the symbolic link at path must be followed
+// whatever the SymlinkPolicy.
+func (o *options) followed(path string) bool {
+	return o.matchesPath(o.follow, path)
+}
+
+// matchesPath reports whether path matches one of patterns, in the syntax
+// described by WithExclude.
+func (o *options) matchesPath(patterns []string, path string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+
+	name := filepath.Base(path)
+	absPath := ""
+	for _, pattern := range patterns {
+		if !strings.ContainsAny(pattern, `/`+string(filepath.Separator)) {
+			if ok, _ := filepath.Match(pattern, name); ok {
+				return true
+			}
+			continue
+		}
+
+		if o.fsys != nil {
+			if path == pattern {
+				return true
+			}
+			continue
+		}
+		if absPath == "" {
+			var err error
+			if absPath, err = filepath.Abs(path); err != nil {
+				absPath = path
+			}
+		}
+		if absPattern, err := filepath.Abs(pattern); err == nil && absPattern == absPath {
+			return true
+		}
+	}
+	return false
+}
+
+// WithExclude keeps a scan out of the directories matching patterns entirely:
+// they are neither reported nor counted towards the size of the directories
+// containing them. Patterns without a separator match directory names, using
+// the syntax of filepath.Match, such as ".git" or "*.bak"; others match the
+// directory at that path, such as "/mnt/backup".
+func WithExclude(patterns ...string) Option {
+	return func(o *options) {
+		o.exclude = append(o.exclude, patterns...)
+	}
+}
+
+// WithAnnotators runs annotators on every directory found by a scan before it
+// is delivered, in the order given.
+func WithAnnotators(annotators ...Annotator) Option {
+	return func(o *options) {
+		o.annotators = append(o.annotators, annotators...)
+	}
+}
+
+// WithMaxDepth stops a scan from looking for directories more than n levels
+// below the root, where the root's immediate children are at level one. The
+// directories found are still measured in full. Values of zero or less mean
+// no limit.
+func WithMaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// WithMiddleware wraps the visitor a scan uses to look for directories in
+// middlewares, the first of which is outermost. Matching directories are
+// measured, together with anything that matches below them, without the
+// visitor.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(o *options) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	}
+}
+
+// WithMatchMode sets how the keywords given by WithKeywords are compared with
+// directory names, MatchExact by default.
+func WithMatchMode(mode MatchMode) Option {
+	return func(o *options) {
+		o.matchMode = mode
+	}
+}
+
+// WithProgress makes StreamDirStat deliver intermediate results, flagged as
+// Partial and with growing sizes, for directories that take longer than
+// interval to measure, so that UIs can show live numbers. ListDirStat ignores
+// them.
+func WithProgress(interval time.Duration) Option {
+	return func(o *options) {
+		o.progress = interval
+	}
+}
+
+// SymlinkPolicy decides how a scan treats symbolic links.
+type SymlinkPolicy int
+
+const (
+	// SkipSymlinks ignores symbolic links.
+	SkipSymlinks SymlinkPolicy = iota
+	// FollowSymlinks measures what symbolic links point to as if it were in
+	// their place. Links leading back to a directory above them are skipped,
+	// so loops cannot hang the scan. Matching directories are only looked for
+	// through real directories.
+	FollowSymlinks
+	// CountSymlinks counts symbolic links in NumberOfSymlinks without
+	// following them.
+	CountSymlinks
+)
+
+// WithSymlinks sets how a scan treats symbolic links, SkipSymlinks by
+// default.
+func WithSymlinks(policy SymlinkPolicy) Option {
+	return func(o *options) {
+		o.symlinks = policy
+	}
+}
+
+// WithFollowSymlinks follows the symbolic links matching patterns, like
+// FollowSymlinks does, even when the SymlinkPolicy is to skip or count links,
+// such as a ~/Documents linked to another drive. Patterns have the syntax of
+// WithExclude: those without a separator match link names, others the link at
+// that path. Links leading back to a directory above them are skipped and
+// reported in SymlinkCycles.
+func WithFollowSymlinks(patterns ...string) Option {
+	return func(o *options) {
+		o.follow = append(o.follow, patterns...)
+	}
+}
+
+// follows reports whether a scan may follow any symbolic link.
+func (o *options) follows() bool {
+	return o.symlinks == FollowSymlinks || len(o.follow) > 0
+}
+
+// WithOneFileSystem keeps a scan on the filesystem of its root, like du -x:
+// directories on other filesystems, such as NFS mounts or /proc, are neither
+// descended into nor counted.
+func WithOneFileSystem() Option {
+	return func(o *options) {
+		o.oneFilesystem = true
+	}
+}
+
+// WithDedupeHardlinks counts files with several hard links once, like du, in
+// the first directory of the tree being measured that they are found in, rather
+// than once for every link. Each directory reported by a scan is measured on
+// its own, so a file linked from two of them counts towards both.
+func WithDedupeHardlinks() Option {
+	return func(o *options) {
+		o.dedupe = true
+	}
+}
+
+// WithFileSizeStats reports the largest file of every directory found by a
+// scan in LargestFile and the average size of its files in AverageFileSize,
+// which are left empty otherwise to save the bookkeeping.
+func WithFileSizeStats() Option {
+	return func(o *options) {
+		o.fileSizes = true
+	}
+}
+
+// WithTiming records in Elapsed how long measuring every directory found by
+// a scan took, so that slow subtrees can be told from the results.
+func WithTiming() Option {
+	return func(o *options) {
+		o.timing = true
+	}
+}
+
+// ErrorPolicy decides how a scan deals with errors, such as directories it
+// is not permitted to read.
+type ErrorPolicy int
+
+const (
+	// SkipAndCollect leaves out the entries that cannot be read, reports the
+	// directories containing them with what could be measured, and returns
+	// the errors alongside the results.
+	SkipAndCollect ErrorPolicy = iota
+	// FailFast stops the scan at the first error and returns only that
+	// error, together with the directories reported before it.
+	FailFast
+	// Ignore is like SkipAndCollect but does not report the errors.
+	Ignore
+)
+
+// WithErrorPolicy sets how ListDirStat, StreamDirStat, DirStat and the
+// functions built on them deal with errors, SkipAndCollect by default.
+func WithErrorPolicy(policy ErrorPolicy) Option {
+	return func(o *options) {
+		o.errorPolicy = policy
+	}
+}
+
+// WithMaxStaleness makes Cache.ListDirStat measure again results that were
+// computed more than d ago, even if they have not expired yet. Scans ignore
+// it otherwise.
+func WithMaxStaleness(d time.Duration) Option {
+	return func(o *options) {
+		o.maxStaleness = d
+	}
+}
+
+// WithDebounce makes a PollWatcher hold back the changes of a directory until
+// it has not changed for d, delivering them coalesced into one, so that a
+// directory being written to in bursts, such as the output of a build, is
+// reported once it settles. A directory that never stops changing is not
+// reported until it does, see WithChangeInterval. Quiet periods are measured
+// in polls, so d is effectively rounded up to the poll interval. Scans ignore
+// it otherwise.
+func WithDebounce(d time.Duration) Option {
+	return func(o *options) {
+		o.debounce = d
+	}
+}
+
+// WithChangeInterval makes a PollWatcher deliver at most one change of a
+// directory every d, coalescing those in between, so that directories
+// changing at every poll do not drown out the rest. Scans ignore it
+// otherwise.
+func WithChangeInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.changeInterval = d
+	}
+}
+
+// WithWorkers sets the number of directories measured concurrently, 8 by
+// default. Values of zero or less keep the default. Local SSDs keep up with
+// runtime.NumCPU() or more; spinning disks do best with 2 to 4, as more
+// workers only add seeks; network filesystems hide their latency best with
+// 16 to 64.
+func WithWorkers(n int) Option {
+	return func(o *options) {
+		o.workers = n
+	}
+}
+
+// WithScheduling sets the order in which matched directories are measured,
+// Interleaved by default.
+func WithScheduling(policy SchedulingPolicy) Option {
+	return func(o *options) {
+		o.scheduling = policy
+	}
+}
+
+// WithMaxOpenFDs limits the directory handles a scan holds open at once to
+// n, so that scans with many workers do not run into the limit on open files,
+// ulimit -n, of systems where it is low. Workers wait for a handle to be
+// closed rather than failing. Scans running out of file descriptors anyway
+// lower the limit, back off and retry without it. The scan needs two handles at least, one to look
+// for directories and one to measure them, and values of zero or less mean no
+// limit.
+func WithMaxOpenFDs(n int) Option {
+	return func(o *options) {
+		o.maxOpenFDs = n
+	}
+}
+
+// WithRemoteMounts makes ScanAllMounts include network filesystems, such as
+// NFS or SMB shares, which are skipped by default.
+func WithRemoteMounts() Option {
+	return func(o *options) {
+		o.remoteMounts = true
+	}
+}
@@ -0,0 +1,48 @@
+package go_walk
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// isWithin reports whether path is parent or a descendant of parent.
+func isWithin(path, parent string) bool {
+	if path == parent {
+		return true
+	}
+	if !strings.HasSuffix(parent, string(filepath.Separator)) {
+		parent += string(filepath.Separator)
+	}
+	return strings.HasPrefix(path, parent)
+}
+
+// pathLess reports whether path a sorts before path b. Unlike comparing the
+// strings directly, the separator sorts before every other character, so that
+// a directory is immediately followed by everything below it: "/a", "/a/b",
+// "/a-b" rather than "/a", "/a-b", "/a/b".
+func pathLess(a, b string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		ca, cb := a[i], b[i]
+		switch {
+		case ca == cb:
+			continue
+		case ca == filepath.Separator:
+			return true
+		case cb == filepath.Separator:
+			return false
+		default:
+			return ca < cb
+		}
+	}
+	return len(a) < len(b)
+}
+
+// depthBelow returns how many levels path is below root, which it must be
+// within: zero for root itself and one for its immediate children.
+func depthBelow(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
@@ -0,0 +1,164 @@
+package go_walk
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"time"
+)
+
+// Phase tells how complete a result of TwoPhaseDirStat is.
+type Phase int
+
+const (
+	// Shallow results carry the counts of a directory's immediate entries
+	// and no size.
+	Shallow Phase = iota
+	// Detailed results carry the full recursive metadata of a directory, as
+	// ListDirStat returns it.
+	Detailed
+)
+
+// PhasedDirectoryInfo is a result of TwoPhaseDirStat.
+type PhasedDirectoryInfo struct {
+	Phase Phase
+	DirectoryInfo
+}
+
+// TwoPhaseDirStat is like StreamDirStat but first delivers a quick Shallow
+// result for every matching directory not nested within another, holding the
+// number of files and subdirectories directly in it, and then a Detailed
+// result for every matching directory with its full size and counts, so that
+// UIs can show the tree at once and fill in the details as they arrive. The
+// first phase reads every directory it looks at once and does not look below
+// matching directories, so that it stays quick without keywords too. All
+// Shallow results are delivered before the first Detailed one, and both
+// phases honour the same options and ErrorPolicy.
+// Partial results of WithProgress are not delivered. Both channels are closed
+// once the scan has finished, and both must be drained until then.
+func TwoPhaseDirStat(dirPath string, opts ...Option) (<-chan PhasedDirectoryInfo, <-chan error) {
+	results := make(chan PhasedDirectoryInfo)
+	errChan := make(chan error)
+
+	go func() {
+		defer close(results)
+		defer close(errChan)
+
+		o := newOptions(opts...)
+		pathStat, err := o.stat(dirPath)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		if !pathStat.IsDir() {
+			errChan <- errors.New("the path provided is not a directory")
+			return
+		}
+
+		m, err := o.matches()
+		if err != nil {
+			errChan <- err
+			return
+		}
+		w, err := newScanWalker(dirPath, o, m)
+		if err != nil {
+			errChan <- err
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		deliver := newReporter(o, func(err error) { errChan <- err }, cancel)
+		// Both phases come across the same unreadable directories, which
+		// are reported the first time only.
+		reported := make(map[string]struct{})
+		report := func(err error) {
+			var errs ErrorList
+			errs.add(err)
+			for _, err := range errs {
+				if path, ok := errorPath(err); ok {
+					if _, exists := reported[path]; exists {
+						continue
+					}
+					reported[path] = struct{}{}
+				}
+				deliver(err)
+			}
+		}
+
+		err = w.walk(ctx, report, func(path string, entry fs.DirEntry) bool {
+			// Reading the directory for its counts is all the first phase
+			// does with it.
+			if result, err := shallowResult(path, entry, o); err != nil {
+				report(err)
+			} else {
+				results <- result
+			}
+			return false
+		})
+		if err != nil {
+			report(err)
+		}
+		if ctx.Err() != nil {
+			// FailFast stopped the scan in the first phase.
+			return
+		}
+
+		dirs, errs, err := streamDirStat(ctx, dirPath, o)
+		if err != nil {
+			report(err)
+			return
+		}
+		for dirs != nil || errs != nil {
+			select {
+			case dir, ok := <-dirs:
+				if !ok {
+					dirs = nil
+					continue
+				}
+				if !dir.Partial {
+					results <- PhasedDirectoryInfo{Phase: Detailed, DirectoryInfo: dir}
+				}
+			case e, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				report(e)
+			}
+		}
+	}()
+
+	return results, errChan
+}
+
+// shallowResult returns the Shallow result for the directory at path.
+func shallowResult(path string, entry fs.DirEntry, o *options) (PhasedDirectoryInfo, error) {
+	info, err := entry.Info()
+	if err != nil {
+		return PhasedDirectoryInfo{}, err
+	}
+
+	entries, err := o.readDir(path)
+	if err != nil {
+		return PhasedDirectoryInfo{}, err
+	}
+
+	result := PhasedDirectoryInfo{
+		Phase: Shallow,
+		DirectoryInfo: DirectoryInfo{
+			Path:         path,
+			CreationTime: info.ModTime(),
+			LastModified: info.ModTime(),
+			ComputedAt:   time.Now(),
+		},
+	}
+	for _, child := range entries {
+		if child.IsDir() {
+			result.NumberOfSubdirs++
+		} else {
+			result.NumberOfFiles++
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,212 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/akshaybabloo/go-walk/fixtures"
+	"github.com/akshaybabloo/go-walk/testfs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoPhaseDirStat(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-two-phase-dir-stat-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	nodeModules := filepath.Join(tmpDir, "project1", "node_modules")
+	err = os.MkdirAll(filepath.Join(nodeModules, "lodash"), 0755)
+	assert.NoError(t, err)
+	for _, file := range []string{filepath.Join(nodeModules, ".package-lock.json"), filepath.Join(nodeModules, "lodash", "index.js")} {
+		err = os.WriteFile(file, []byte("test"), 0644)
+		assert.NoError(t, err)
+	}
+
+	results, errChan := TwoPhaseDirStat(tmpDir, WithKeywords("node_modules"))
+
+	var phases []PhasedDirectoryInfo
+	for results != nil || errChan != nil {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				results = nil
+				continue
+			}
+			phases = append(phases, result)
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			assert.NoError(t, err)
+		}
+	}
+
+	assert.Len(t, phases, 2)
+	if len(phases) != 2 {
+		return
+	}
+
+	assert.Equal(t, Shallow, phases[0].Phase)
+	assert.Equal(t, nodeModules, phases[0].Path)
+	assert.Equal(t, int64(0), phases[0].Size)
+	assert.Equal(t, 1, phases[0].NumberOfFiles)
+	assert.Equal(t, 1, phases[0].NumberOfSubdirs)
+
+	assert.Equal(t, Detailed, phases[1].Phase)
+	assert.Equal(t, nodeModules, phases[1].Path)
+	assert.Equal(t, int64(8), phases[1].Size)
+	assert.Equal(t, 2, phases[1].NumberOfFiles)
+}
+
+// drainPhases collects everything TwoPhaseDirStat delivers.
+func drainPhases(results <-chan PhasedDirectoryInfo, errChan <-chan error) ([]PhasedDirectoryInfo, []error) {
+	var phases []PhasedDirectoryInfo
+	var errs []error
+	for results != nil || errChan != nil {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				results = nil
+				continue
+			}
+			phases = append(phases, result)
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			errs = append(errs, err)
+		}
+	}
+	return phases, errs
+}
+
+func TestTwoPhaseDirStatWithErrorPolicy(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for the superuser")
+	}
+
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"project1/node_modules/index.js": fixtures.File(4),
+		"project2/":                      {Mode: 0200},
+	})
+
+	phases, errs := drainPhases(TwoPhaseDirStat(tmpDir, WithKeywords("node_modules")))
+	assert.Len(t, phases, 2)
+	if assert.Len(t, errs, 1) {
+		assert.ErrorIs(t, errs[0], os.ErrPermission)
+	}
+
+	phases, errs = drainPhases(TwoPhaseDirStat(tmpDir, WithKeywords("node_modules"), WithErrorPolicy(Ignore)))
+	assert.Len(t, phases, 2)
+	assert.Empty(t, errs)
+
+	// The first error stops the scan before the second phase
+	_, errs = drainPhases(TwoPhaseDirStat(tmpDir, WithKeywords("node_modules"), WithErrorPolicy(FailFast)))
+	assert.Len(t, errs, 1)
+}
+
+func TestTwoPhaseDirStatReads(t *testing.T) {
+	fsys := testfs.New(fstest.MapFS{
+		"project1/node_modules/a/index.js":                {Data: []byte("test")},
+		"project1/node_modules/a/node_modules/b/index.js": {Data: []byte("test")},
+	})
+	withFS := func(o *options) { o.fsys = fsys }
+
+	phases, errs := drainPhases(TwoPhaseDirStat(".", withFS, WithKeywords("node_modules")))
+	assert.Empty(t, errs)
+	var shallow, detailed []string
+	for _, phase := range phases {
+		if phase.Phase == Shallow {
+			shallow = append(shallow, phase.Path)
+		} else {
+			detailed = append(detailed, phase.Path)
+		}
+	}
+	assert.Equal(t, []string{"project1/node_modules"}, shallow)
+	assert.ElementsMatch(t, []string{"project1/node_modules", "project1/node_modules/a/node_modules"}, detailed)
+
+	// Once for its counts and once measuring it, and nothing below it is
+	// read by the first phase
+	reads := fsys.Reads()
+	assert.Equal(t, 2, reads["project1/node_modules"])
+	assert.Equal(t, 1, reads["project1/node_modules/a"])
+	assert.Equal(t, 1, reads["project1/node_modules/a/node_modules/b"])
+}
+
+func TestTwoPhaseDirStatReportsOnce(t *testing.T) {
+	fsys := testfs.New(fstest.MapFS{
+		"project1/node_modules/index.js":         {Data: []byte("test")},
+		"project1/node_modules/private/index.js": {Data: []byte("test")},
+		"project2/private/index.js":              {Data: []byte("test")},
+		"project2/node_modules/index.js":         {Data: []byte("test")},
+	})
+	fsys.Deny("project1/node_modules/private")
+	fsys.Deny("project2/private")
+	withFS := func(o *options) { o.fsys = fsys }
+
+	// Each unreadable directory once, although both phases come across it
+	_, errs := drainPhases(TwoPhaseDirStat(".", withFS, WithKeywords("node_modules")))
+	var paths []string
+	for _, err := range errs {
+		path, _ := errorPath(err)
+		paths = append(paths, path)
+	}
+	assert.ElementsMatch(t, []string{"project1/node_modules/private", "project2/private"}, paths)
+}
+
+func TestTwoPhaseDirStatWithProgress(t *testing.T) {
+	fsys := testfs.New(fstest.MapFS{
+		"node_modules/a/index.js": {Data: []byte("test")},
+		"node_modules/b/index.js": {Data: []byte("test")},
+		"node_modules/c/index.js": {Data: []byte("test")},
+	})
+	fsys.SetLatency(5 * time.Millisecond)
+	withFS := func(o *options) { o.fsys = fsys }
+
+	// Partial results are not mistaken for Detailed ones
+	phases, errs := drainPhases(TwoPhaseDirStat(".", withFS, WithKeywords("node_modules"), WithProgress(time.Millisecond)))
+	assert.Empty(t, errs)
+	var detailed []PhasedDirectoryInfo
+	for _, phase := range phases {
+		assert.False(t, phase.Partial)
+		if phase.Phase == Detailed {
+			detailed = append(detailed, phase)
+		}
+	}
+	if assert.Len(t, detailed, 1) {
+		assert.Equal(t, int64(12), detailed[0].Size)
+	}
+}
+
+func TestTwoPhaseDirStatWithOneFileSystem(t *testing.T) {
+	// /dev/shm and /dev/pts are usually separate filesystems mounted on /dev
+	rootDev, ok, err := newOptions().deviceOf("/dev")
+	if err != nil || !ok {
+		t.Skip("device numbers of /dev are not available")
+	}
+
+	var mountPoints []string
+	for _, dir := range []string{"/dev/shm", "/dev/pts"} {
+		if dev, _, err := newOptions().deviceOf(dir); err == nil && dev != rootDev {
+			mountPoints = append(mountPoints, dir)
+		}
+	}
+	if len(mountPoints) == 0 {
+		t.Skip("no filesystems are mounted below /dev")
+	}
+
+	phases, _ := drainPhases(TwoPhaseDirStat("/dev", WithOneFileSystem(), WithMaxDepth(1)))
+	assert.NotEmpty(t, phases)
+	for _, phase := range phases {
+		assert.NotContains(t, mountPoints, phase.Path)
+	}
+}
@@ -0,0 +1,141 @@
+package go_walk
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PresetMetadata describes a preset for reports.
+type PresetMetadata struct {
+	Description string        `json:"description" yaml:"description"` // What the matched directories hold, e.g. "installed npm packages".
+	Ecosystem   string        `json:"ecosystem" yaml:"ecosystem"`     // Language or tool the preset belongs to, e.g. "JavaScript".
+	Retention   time.Duration `json:"retention" yaml:"retention"`     // How long matched directories may go unmodified before PresetRetention reports them, forever if zero.
+}
+
+// day is the length of a day, in which retention periods are given.
+const day = 24 * time.Hour
+
+// preset is a named set of directory name patterns.
+type preset struct {
+	patterns []string
+	metadata PresetMetadata
+}
+
+var (
+	presetsMu sync.RWMutex
+	// presets maps a preset name to the directory names it matches.
+	presets = map[string]preset{
+		"node": {
+			patterns: []string{"node_modules", ".next", ".nuxt", ".turbo", ".parcel-cache"},
+			metadata: PresetMetadata{Description: "installed packages and framework build caches", Ecosystem: "JavaScript", Retention: 60 * day},
+		},
+		"python": {
+			patterns: []string{"__pycache__", ".venv", "venv", ".pytest_cache", ".mypy_cache", ".tox"},
+			metadata: PresetMetadata{Description: "bytecode, tool caches and virtual environments", Ecosystem: "Python", Retention: 30 * day},
+		},
+		"rust": {
+			patterns: []string{"target"},
+			metadata: PresetMetadata{Description: "Cargo build output", Ecosystem: "Rust", Retention: 30 * day},
+		},
+		"gradle": {
+			patterns: []string{".gradle", "build"},
+			// Without retention periods by default, as build, like bin for
+			// dotnet, is too common a name of other directories.
+			metadata: PresetMetadata{Description: "Gradle caches and build output", Ecosystem: "JVM"},
+		},
+		"dotnet": {
+			patterns: []string{"bin", "obj"},
+			metadata: PresetMetadata{Description: "MSBuild output", Ecosystem: ".NET"},
+		},
+		"xcode": {
+			patterns: []string{"DerivedData"},
+			metadata: PresetMetadata{Description: "Xcode build products and indexes", Ecosystem: "Apple", Retention: 30 * day},
+		},
+	}
+)
+
+// crashDumps matches the names of crash dump files: "core", "core.<pid>",
+// systemd-coredump archives and Windows minidumps. It is kept apart from the
+// directory presets, which would otherwise claim every directory named core.
+var crashDumps = preset{
+	patterns: []string{"core", "core.[0-9]*", "core.*.zst", "core.*.lz4", "core.*.xz", "*.dmp", "*.mdmp"},
+	metadata: PresetMetadata{Description: "crash dumps"},
+}
+
+// matches reports whether name matches one of the patterns of p.
+func (p preset) matches(name string) bool {
+	for _, pattern := range p.patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterPreset adds the preset name, matching directories whose name
+// matches one of patterns in the syntax of filepath.Match, replacing any
+// preset of that name. Registered presets work wherever the built-in ones
+// do, such as in Preset, Presets and PresetAnnotator. It is safe for
+// concurrent use.
+func RegisterPreset(name string, patterns []string, metadata PresetMetadata) {
+	presetsMu.Lock()
+	defer presetsMu.Unlock()
+	presets[name] = preset{patterns: append([]string(nil), patterns...), metadata: metadata}
+}
+
+// SetPresetRetention overrides the retention period of the preset name,
+// where zero disables PresetRetention for it. It reports whether the preset exists.
+// It is safe for concurrent use.
+func SetPresetRetention(name string, retention time.Duration) bool {
+	presetsMu.Lock()
+	defer presetsMu.Unlock()
+
+	p, exists := presets[name]
+	if !exists {
+		return false
+	}
+	p.metadata.Retention = retention
+	presets[name] = p
+	return true
+}
+
+// Presets returns the names of the available presets in sorted order.
+func Presets() []string {
+	presetsMu.RLock()
+	defer presetsMu.RUnlock()
+
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupPreset returns the patterns and metadata of the preset name. The last
+// return value reports whether the preset exists.
+func LookupPreset(name string) ([]string, PresetMetadata, bool) {
+	presetsMu.RLock()
+	defer presetsMu.RUnlock()
+
+	p, exists := presets[name]
+	return append([]string(nil), p.patterns...), p.metadata, exists
+}
+
+// presetsMatching returns the names of the presets matching the directory
+// name in sorted order.
+func presetsMatching(name string) []string {
+	presetsMu.RLock()
+	defer presetsMu.RUnlock()
+
+	var matched []string
+	for presetName, p := range presets {
+		if p.matches(name) {
+			matched = append(matched, presetName)
+		}
+	}
+	sort.Strings(matched)
+	return matched
+}
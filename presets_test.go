@@ -0,0 +1,55 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterPreset(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-register-preset-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	zigCache := filepath.Join(tmpDir, "app", "zig-cache")
+	zigOut := filepath.Join(tmpDir, "app", "zig-out")
+	src := filepath.Join(tmpDir, "app", "src")
+
+	for _, dir := range []string{zigCache, zigOut, src} {
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(t, err)
+	}
+
+	metadata := PresetMetadata{Description: "Zig build cache and output", Ecosystem: "Zig"}
+	RegisterPreset("test-zig", []string{"zig-*"}, metadata)
+	defer func() {
+		presetsMu.Lock()
+		delete(presets, "test-zig")
+		presetsMu.Unlock()
+	}()
+
+	assert.Contains(t, Presets(), "test-zig")
+
+	patterns, got, ok := LookupPreset("test-zig")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"zig-*"}, patterns)
+	assert.Equal(t, metadata, got)
+
+	_, _, ok = LookupPreset("does-not-exist")
+	assert.False(t, ok)
+
+	directories, err := ListDirStatMatching(tmpDir, Preset("test-zig"), WithAnnotators(PresetAnnotator()))
+	assert.NoError(t, err)
+
+	annotations := make(map[string]string)
+	for _, dir := range directories {
+		annotations[dir.Path] = dir.Annotations["preset"]
+	}
+	assert.Equal(t, map[string]string{zigCache: "test-zig", zigOut: "test-zig"}, annotations)
+}
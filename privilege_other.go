@@ -0,0 +1,13 @@
+//go:build !unix && !windows
+
+package go_walk
+
+// privilegeDetection reports whether isPrivileged can detect privileges on this
+// platform.
+const privilegeDetection = false
+
+// isPrivileged reports whether the process runs with administrative
+// privileges.
+func isPrivileged() bool {
+	return false
+}
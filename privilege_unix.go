@@ -0,0 +1,14 @@
+//go:build unix
+
+package go_walk
+
+import "os"
+
+// privilegeDetection reports whether isPrivileged can detect privileges on this
+// platform.
+const privilegeDetection = true
+
+// isPrivileged reports whether the process runs as the superuser.
+func isPrivileged() bool {
+	return os.Geteuid() == 0
+}
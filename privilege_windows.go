@@ -0,0 +1,33 @@
+package go_walk
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// tokenElevation is the TOKEN_INFORMATION_CLASS value for querying whether a
+// token is elevated.
+const tokenElevation = 20
+
+// privilegeDetection reports whether isPrivileged can detect privileges on this
+// platform.
+const privilegeDetection = true
+
+// isPrivileged reports whether the process runs elevated.
+func isPrivileged() bool {
+	process, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return false
+	}
+
+	var token syscall.Token
+	if err := syscall.OpenProcessToken(process, syscall.TOKEN_QUERY, &token); err != nil {
+		return false
+	}
+	defer token.Close()
+
+	var elevated uint32
+	var n uint32
+	err = syscall.GetTokenInformation(token, tokenElevation, (*byte)(unsafe.Pointer(&elevated)), uint32(unsafe.Sizeof(elevated)), &n)
+	return err == nil && elevated != 0
+}
@@ -0,0 +1,60 @@
+package go_walk
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// ReportSchemaVersion is the version of the schema written by MarshalReport.
+// It changes only when fields are removed or change meaning, so consumers
+// should ignore fields they do not know.
+const ReportSchemaVersion = 1
+
+// Report is the document written by MarshalReport. Times are in UTC in the
+// RFC 3339 profile of ISO 8601, sizes in bytes and durations in nanoseconds.
+// Fields of DirectoryInfo that were not measured are left out or zero, as
+// documented on DirectoryInfo.
+type Report struct {
+	SchemaVersion int             `json:"schema_version" yaml:"schema_version"` // Version of the schema, ReportSchemaVersion.
+	GeneratedAt   time.Time       `json:"generated_at" yaml:"generated_at"`     // When the report was written.
+	Root          string          `json:"root,omitempty" yaml:"root,omitempty"` // Directory that was scanned, if known.
+	Directories   []DirectoryInfo `json:"directories" yaml:"directories"`       // Directories found by the scan.
+}
+
+// JSONOptions configures the output of MarshalReport.
+type JSONOptions struct {
+	Indent string // Indentation of nested elements, such as "  ", compact output on one line if empty.
+	Root   string // Directory that was scanned, recorded in the report.
+}
+
+// MarshalReport writes dirs to w as a JSON Report, the stable format meant
+// for other tools to consume.
+func MarshalReport(w io.Writer, dirs []DirectoryInfo, format JSONOptions) error {
+	report := Report{
+		SchemaVersion: ReportSchemaVersion,
+		GeneratedAt:   time.Now().UTC(),
+		Root:          format.Root,
+		Directories:   cloneDirectories(dirs),
+	}
+	if report.Directories == nil {
+		report.Directories = []DirectoryInfo{}
+	}
+	for i := range report.Directories {
+		report.Directories[i].inUTC()
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", format.Indent)
+	return encoder.Encode(report)
+}
+
+// inUTC converts the times of d to UTC.
+func (d *DirectoryInfo) inUTC() {
+	for _, t := range []*time.Time{&d.CreationTime, &d.LastModified, &d.ComputedAt, &d.NewestFileModTime, &d.OldestFileModTime} {
+		*t = t.UTC()
+	}
+	if d.LargestFile != nil {
+		d.LargestFile.LastModified = d.LargestFile.LastModified.UTC()
+	}
+}
@@ -0,0 +1,71 @@
+package go_walk
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalReport(t *testing.T) {
+	auckland := time.FixedZone("NZST", 12*60*60)
+	dirs := []DirectoryInfo{{
+		Path:            "/home/user/project/node_modules",
+		Size:            12,
+		SizeOnDisk:      8192,
+		CreationTime:    time.Date(2024, 1, 2, 15, 4, 5, 0, auckland),
+		LastModified:    time.Date(2024, 6, 7, 20, 9, 10, 0, auckland),
+		NumberOfFiles:   1,
+		NumberOfSubdirs: 2,
+		ComputedAt:      time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC),
+		ByExtension:     map[string]ExtStat{".js": {Count: 1, Size: 12}},
+	}}
+
+	var buf bytes.Buffer
+	err := MarshalReport(&buf, dirs, JSONOptions{Root: "/home/user"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+
+	var report map[string]any
+	err = json.Unmarshal(buf.Bytes(), &report)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(ReportSchemaVersion), report["schema_version"])
+	assert.Equal(t, "/home/user", report["root"])
+	assert.Contains(t, report, "generated_at")
+
+	data, err := json.Marshal(report["directories"])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{
+		"path": "/home/user/project/node_modules",
+		"size": 12,
+		"size_on_disk": 8192,
+		"creation_time": "2024-01-02T03:04:05Z",
+		"last_modified": "2024-06-07T08:09:10Z",
+		"number_of_files": 1,
+		"number_of_subdirs": 2,
+		"computed_at": "2024-06-08T00:00:00Z",
+		"newest_file_mod_time": "0001-01-01T00:00:00Z",
+		"oldest_file_mod_time": "0001-01-01T00:00:00Z",
+		"by_extension": {".js": {"count": 1, "size": 12}}
+	}]`, string(data))
+
+	// The directories given are left as they were.
+	assert.Equal(t, auckland, dirs[0].CreationTime.Location())
+}
+
+func TestMarshalReportIndented(t *testing.T) {
+	var buf bytes.Buffer
+	err := MarshalReport(&buf, nil, JSONOptions{Indent: "  "})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "\n  \"directories\": []")
+
+	var report Report
+	err = json.Unmarshal(buf.Bytes(), &report)
+	assert.NoError(t, err)
+	assert.Equal(t, ReportSchemaVersion, report.SchemaVersion)
+	assert.Empty(t, report.Root)
+	assert.WithinDuration(t, time.Now(), report.GeneratedAt, time.Minute)
+}
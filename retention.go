@@ -0,0 +1,83 @@
+package go_walk
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// PresetRetention returns a Rule, for use with Check, that is broken by every
+// directory below root matching one of the presets names, or any preset if
+// none are given, that has not been modified for longer than the retention
+// period of the preset, such as 60 days for node_modules. Directories matching
+// several presets get the shortest period. Presets matching names that other
+// directories commonly have, such as "bin" for dotnet or "build" for gradle,
+// have no period by default. The periods can be changed with
+// SetPresetRetention or RegisterPreset.
+func PresetRetention(names ...string) Rule {
+	return &retentionRule{names: names, now: time.Now}
+}
+
+// retentionRule is the Rule returned by PresetRetention.
+type retentionRule struct {
+	names []string
+	now   func() time.Time
+}
+
+// Name returns a short description of the rule.
+func (r *retentionRule) Name() string {
+	return "preset retention"
+}
+
+// Violations returns the directories below root kept longer than their
+// presets allow.
+func (r *retentionRule) Violations(root string) ([]Violation, error) {
+	names := r.names
+	if len(names) == 0 {
+		names = Presets()
+	}
+
+	m := MatcherFunc(func(_ string, d fs.DirEntry) bool {
+		_, retention := shortestRetention(names, d.Name())
+		return retention > 0
+	})
+	directories, err := ListDirStatMatching(root, belowRoot(root, m))
+
+	now := r.now()
+	var violations []Violation
+	for _, dir := range directories {
+		name, retention := shortestRetention(names, filepath.Base(dir.Path))
+		changed := dir.lastChanged()
+		age := now.Sub(changed)
+		if retention <= 0 || changed.IsZero() || age <= retention {
+			continue
+		}
+		violations = append(violations, Violation{
+			Rule:   r.Name(),
+			Path:   dir.Path,
+			Size:   dir.Size,
+			Reason: fmt.Sprintf("unmodified for %d days, longer than the %d days kept for preset %s", age/day, retention/day, name),
+		})
+	}
+	return violations, err
+}
+
+// shortestRetention returns the preset among names matching the directory
+// name with the shortest retention period, and that period, which is zero if
+// none has one.
+func shortestRetention(names []string, name string) (string, time.Duration) {
+	var shortest string
+	var retention time.Duration
+	for _, preset := range presetsMatching(name) {
+		if !slices.Contains(names, preset) {
+			continue
+		}
+		_, metadata, _ := LookupPreset(preset)
+		if metadata.Retention > 0 && (retention == 0 || metadata.Retention < retention) {
+			shortest, retention = preset, metadata.Retention
+		}
+	}
+	return shortest, retention
+}
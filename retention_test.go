@@ -0,0 +1,65 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresetRetention(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-preset-retention-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	nodeModules := filepath.Join(tmpDir, "web", "node_modules")
+	target := filepath.Join(tmpDir, "cli", "target")
+	src := filepath.Join(tmpDir, "cli", "src")
+	// Matched by the dotnet and gradle presets, but just as likely not
+	// build output at all
+	bin := filepath.Join(tmpDir, "cli", "scripts", "bin")
+	build := filepath.Join(tmpDir, "cli", "build")
+
+	for _, dir := range []string{nodeModules, target, src, bin, build} {
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(t, err)
+	}
+
+	// All of them were last modified 45 days ago
+	old := time.Now().Add(-45 * day)
+	for _, dir := range []string{nodeModules, target, src, bin, build} {
+		err = os.Chtimes(dir, old, old)
+		assert.NoError(t, err)
+	}
+
+	result, err := Check(tmpDir, PresetRetention())
+	assert.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Len(t, result.Violations, 1)
+	if len(result.Violations) == 1 {
+		assert.Equal(t, target, result.Violations[0].Path)
+		assert.Equal(t, "preset retention", result.Violations[0].Rule)
+		assert.Equal(t, "unmodified for 45 days, longer than the 30 days kept for preset rust", result.Violations[0].Reason)
+	}
+
+	// Only the given presets are checked
+	result, err = Check(tmpDir, PresetRetention("node"))
+	assert.NoError(t, err)
+	assert.True(t, result.Passed)
+
+	// Retention periods can be overridden
+	_, metadata, _ := LookupPreset("node")
+	assert.True(t, SetPresetRetention("node", 30*day))
+	defer SetPresetRetention("node", metadata.Retention)
+	assert.False(t, SetPresetRetention("missing", day))
+
+	result, err = Check(tmpDir, PresetRetention())
+	assert.NoError(t, err)
+	assert.Len(t, result.Violations, 2)
+}
@@ -0,0 +1,498 @@
+package go_walk
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// dirStats accumulates the statistics of a directory tree.
+type dirStats struct {
+	size     int64
+	onDisk   int64
+	files    int
+	subdirs  int
+	symlinks int
+	skipped  int
+	earliest time.Time
+	latest   time.Time
+	newest   time.Time          // Latest modification of a file.
+	oldest   time.Time          // Earliest modification of a file.
+	largest  FileInfo           // Largest file, tracked only with WithFileSizeStats, none if its Path is empty.
+	byExt    map[string]ExtStat // Files by extension, tracked only with WithExtensionStats.
+	elapsed  time.Duration      // How long measuring the tree took, tracked only with WithTiming.
+	cycles   []SymlinkCycle     // Symbolic links leading back to a directory above them.
+}
+
+// addFile considers the file described by f as the largest.
+func (s *dirStats) addFile(f FileInfo) {
+	if s.largest.Path == "" || f.Size > s.largest.Size {
+		s.largest = f
+	}
+}
+
+// addTime records an entry last modified at t.
+func (s *dirStats) addTime(t time.Time) {
+	if s.earliest.IsZero() || t.Before(s.earliest) {
+		s.earliest = t
+	}
+	if s.latest.IsZero() || t.After(s.latest) {
+		s.latest = t
+	}
+}
+
+// addFileTime records a file last modified at t.
+func (s *dirStats) addFileTime(t time.Time) {
+	s.addTime(t)
+	if s.oldest.IsZero() || t.Before(s.oldest) {
+		s.oldest = t
+	}
+	if s.newest.IsZero() || t.After(s.newest) {
+		s.newest = t
+	}
+}
+
+// merge adds the statistics of a subtree.
+func (s *dirStats) merge(sub dirStats) {
+	s.size += sub.size
+	s.onDisk += sub.onDisk
+	s.files += sub.files
+	s.subdirs += sub.subdirs
+	s.symlinks += sub.symlinks
+	s.skipped += sub.skipped
+	if !sub.earliest.IsZero() {
+		s.addTime(sub.earliest)
+	}
+	if !sub.latest.IsZero() {
+		s.addTime(sub.latest)
+	}
+	if !sub.oldest.IsZero() {
+		s.addFileTime(sub.oldest)
+	}
+	if !sub.newest.IsZero() {
+		s.addFileTime(sub.newest)
+	}
+	if sub.largest.Path != "" {
+		s.addFile(sub.largest)
+	}
+	for ext, stat := range sub.byExt {
+		s.byExt = addExtension(s.byExt, ext, stat.Count, stat.Size)
+	}
+	s.cycles = append(s.cycles, sub.cycles...)
+}
+
+// info returns the statistics as the DirectoryInfo of path.
+func (s dirStats) info(path string) DirectoryInfo {
+	info := DirectoryInfo{
+		Path:              path,
+		Size:              s.size,
+		SizeOnDisk:        s.onDisk,
+		CreationTime:      s.earliest,
+		LastModified:      s.latest,
+		NewestFileModTime: s.newest,
+		OldestFileModTime: s.oldest,
+		NumberOfFiles:     s.files,
+		NumberOfSubdirs:   s.subdirs,
+		NumberOfSymlinks:  s.symlinks,
+		ComputedAt:        time.Now(),
+		Incomplete:        s.skipped > 0,
+		SkippedEntries:    s.skipped,
+		Elapsed:           s.elapsed,
+		SymlinkCycles:     slices.Clone(s.cycles),
+	}
+	if s.largest.Path != "" {
+		largest := s.largest
+		info.LargestFile = &largest
+		info.AverageFileSize = s.size / int64(s.files)
+	}
+	if len(s.byExt) > 0 {
+		// The subtree's map is still merged into the directories above.
+		info.ByExtension = make(map[string]ExtStat, len(s.byExt))
+		for ext, stat := range s.byExt {
+			info.ByExtension[ext] = stat
+		}
+	}
+	return info
+}
+
+// rollup computes the statistics of a directory tree in a single pass,
+// bottom-up, handing the statistics of every nested directory that matches
+// to emit as soon as they are complete. Entries that cannot be read are left
+// out, unless the error policy is FailFast, in which case the first error
+// stops the rollup. This way directories nested within
+// each other, such as node_modules within node_modules, are read only once,
+// and a scan without keywords reads the whole tree once, the way du does.
+type rollup struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	o        *options
+	scanRoot string              // Root of the whole scan, which maxDepth is relative to.
+	m        Matcher             // Nested directories to emit, all if nil.
+	emit     func(DirectoryInfo) // Receives matching nested directories, none if nil.
+	rootDev  uint64
+	checkDev bool
+
+	root string        // Directory being measured.
+	sem  chan struct{} // Limits the subdirectories of root measured concurrently, one at a time if nil.
+
+	blockOnce sync.Once // Looks up blockSize the first time it is needed.
+	blockSize int64     // Allocation unit of the filesystem of root, for platforms without block counts.
+
+	linksMu sync.Mutex          // Guards links.
+	links   map[fileID]struct{} // Files with several hard links counted so far.
+
+	failOnce sync.Once // Records failErr.
+	failErr  error     // First error, which stopped the rollup with FailFast.
+
+	mu       sync.Mutex          // Guards the progress of root.
+	running  dirStats            // What has been measured of root so far.
+	interval time.Duration       // How often to report progress, never if zero.
+	next     time.Time           // When to report progress next.
+	progress func(DirectoryInfo) // Receives the progress of root.
+	visited  int                 // Files visited since progress was last checked.
+}
+
+// newRollup returns a rollup of the tree at path, part of the scan of
+// scanRoot, according to o.
+func newRollup(ctx context.Context, scanRoot, path string, o *options, m Matcher, emit func(DirectoryInfo)) (*rollup, error) {
+	r := &rollup{o: o, scanRoot: scanRoot, m: m, emit: emit, root: path}
+	r.ctx, r.cancel = context.WithCancel(ctx)
+	if o.oneFilesystem {
+		var err error
+		r.rootDev, r.checkDev, err = o.deviceOf(scanRoot)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// progressCheckEvery is how many files are visited between checks of whether
+// progress is due.
+const progressCheckEvery = 256
+
+// concurrently lets the rollup measure up to n subdirectories of the root at
+// once.
+func (r *rollup) concurrently(n int) {
+	if n > 1 {
+		r.sem = make(chan struct{}, n)
+	}
+}
+
+// onProgress makes the rollup report what it has measured so far to progress
+// every interval.
+func (r *rollup) onProgress(interval time.Duration, progress func(DirectoryInfo)) {
+	r.interval = interval
+	r.next = time.Now().Add(interval)
+	r.progress = progress
+}
+
+// allocated returns the space allocated on disk to the entry described by
+// info.
+func (r *rollup) allocated(info fs.FileInfo) int64 {
+	if _, ok := statOf(info); !ok && r.o.fsys == nil {
+		r.blockOnce.Do(func() {
+			r.blockSize, _ = FilesystemBlockSize(r.root)
+		})
+	}
+	return allocatedSize(info, r.blockSize)
+}
+
+// counted reports whether the file described by info has already been
+// counted through another hard link, and remembers it otherwise.
+func (r *rollup) counted(info fs.FileInfo) bool {
+	st, ok := statOf(info)
+	if !ok || st.nlink < 2 {
+		return false
+	}
+
+	r.linksMu.Lock()
+	defer r.linksMu.Unlock()
+
+	id := fileID{dev: st.dev, ino: st.ino}
+	if _, exists := r.links[id]; exists {
+		return true
+	}
+	if r.links == nil {
+		r.links = make(map[fileID]struct{})
+	}
+	r.links[id] = struct{}{}
+	return false
+}
+
+// record adds an entry of size bytes, onDisk of them allocated, a file rather
+// than a directory if file is set, last modified at t to the running total and
+// reports progress if due.
+func (r *rollup) record(size, onDisk int64, file bool, t time.Time) {
+	if r.progress == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.running.onDisk += onDisk
+	if !file {
+		r.running.addTime(t)
+		r.running.subdirs++
+		return
+	}
+	r.running.addFileTime(t)
+	r.running.size += size
+	r.running.files++
+
+	r.visited++
+	if r.visited < progressCheckEvery {
+		return
+	}
+	r.visited = 0
+	if now := time.Now(); now.After(r.next) {
+		r.next = now.Add(r.interval)
+		r.progress(r.running.info(r.root))
+	}
+}
+
+// run returns the statistics of the directory at path, emitting matching
+// directories below it along the way. If errors occurred, they are returned
+// together with what could be measured. measured is false if nothing could
+// be, because path itself could not be stat'ed or the rollup failed.
+func (r *rollup) run(path string) (dirStat DirectoryInfo, measured bool, err error) {
+	defer r.cancel()
+
+	info, err := r.o.stat(path)
+	if err != nil {
+		return DirectoryInfo{}, false, err
+	}
+
+	stats, err := r.walk(path, info, nil)
+	if r.failErr != nil {
+		return DirectoryInfo{}, false, r.failErr
+	}
+	return stats.info(path), true, err
+}
+
+// fail returns whether err ends the rollup, recording it and stopping the
+// rollup if so.
+func (r *rollup) fail(err error) bool {
+	if r.o.errorPolicy != FailFast {
+		return false
+	}
+	r.failOnce.Do(func() {
+		r.failErr = err
+		r.cancel()
+	})
+	return true
+}
+
+// walk returns the statistics of the directory at path, whose own metadata
+// is info and whose ancestors, when following symbolic links, are identified
+// by ancestors, together with the errors that occurred below it.
+func (r *rollup) walk(path string, info fs.FileInfo, ancestors []ancestor) (dirStats, error) {
+	var start time.Time
+	if r.o.timing {
+		start = time.Now()
+	}
+
+	stats := dirStats{subdirs: 1, onDisk: r.allocated(info)}
+	stats.addTime(info.ModTime())
+	r.record(0, stats.onDisk, false, info.ModTime())
+
+	if err := r.ctx.Err(); err != nil {
+		return stats, err
+	}
+
+	if r.o.follows() {
+		// Remember the directories above, to recognise symbolic links
+		// leading back to one of them.
+		ancestors = append(ancestors[:len(ancestors):len(ancestors)], ancestor{key: r.dirKey(path, info), path: path})
+	}
+
+	// Entries read before an error are still measured.
+	var errs ErrorList
+	entries, err := r.o.readDir(path)
+	if err != nil {
+		if r.fail(err) {
+			return stats, err
+		}
+		errs.add(err)
+		stats.skipped++
+	}
+
+	// Subdirectories of the root are measured concurrently when the rollup
+	// has workers to itself.
+	concurrent := r.sem != nil && path == r.root
+	var wg sync.WaitGroup
+	children := make([]subtree, 0, len(entries))
+
+	// Once the rollup stops, no more subdirectories are started, but those
+	// already being measured are waited for, so that none of them emits
+	// anything after the rollup has returned.
+	var stop error
+	for _, entry := range entries {
+		if err := r.ctx.Err(); err != nil {
+			stop = err
+			break
+		}
+
+		p := r.o.join(path, entry.Name())
+		if entry.IsDir() && r.o.excluded(p) {
+			continue
+		}
+
+		childInfo, err := entry.Info()
+		if err != nil {
+			if r.fail(err) {
+				stop = err
+				break
+			}
+			errs.add(err)
+			stats.skipped++
+			continue
+		}
+
+		isDir := entry.IsDir()
+		if childInfo.Mode()&fs.ModeSymlink != 0 {
+			policy := r.o.symlinks
+			if r.o.followed(p) {
+				policy = FollowSymlinks
+			}
+			switch policy {
+			case CountSymlinks:
+				stats.symlinks++
+				continue
+			case FollowSymlinks:
+				target, err := r.o.stat(p)
+				if err != nil {
+					// Dangling links have nothing to measure.
+					continue
+				}
+				if target.IsDir() {
+					key := r.dirKey(p, target)
+					if i := slices.IndexFunc(ancestors, func(a ancestor) bool { return a.key == key }); i >= 0 {
+						stats.cycles = append(stats.cycles, SymlinkCycle{Link: p, Target: ancestors[i].path})
+						continue
+					}
+				}
+				childInfo, isDir = target, target.IsDir()
+			default:
+				continue
+			}
+		}
+
+		if !isDir {
+			if r.o.files != nil && !r.o.files.Match(p, entry) {
+				continue
+			}
+			if r.o.dedupe && r.counted(childInfo) {
+				continue
+			}
+			onDisk := r.allocated(childInfo)
+			stats.size += childInfo.Size()
+			stats.onDisk += onDisk
+			stats.files++
+			stats.addFileTime(childInfo.ModTime())
+			if r.o.fileSizes {
+				stats.addFile(FileInfo{Path: p, Size: childInfo.Size(), LastModified: childInfo.ModTime(), Extension: extensionOf(entry.Name())})
+			}
+			if r.o.extensions {
+				stats.byExt = addExtension(stats.byExt, extensionOf(entry.Name()), 1, childInfo.Size())
+			}
+			r.record(childInfo.Size(), onDisk, true, childInfo.ModTime())
+			continue
+		}
+
+		if r.checkDev {
+			if st, ok := statOf(childInfo); ok && st.dev != r.rootDev {
+				continue
+			}
+		}
+
+		children = append(children, subtree{path: p, entry: entry})
+		child := &children[len(children)-1]
+		if !concurrent {
+			child.stats, child.err = r.walk(p, childInfo, ancestors)
+			if child.err != nil && r.fail(child.err) {
+				stop = child.err
+				break
+			}
+			continue
+		}
+
+		wg.Add(1)
+		r.sem <- struct{}{}
+		go func(child *subtree, info fs.FileInfo) {
+			defer wg.Done()
+			defer func() { <-r.sem }()
+			child.stats, child.err = r.walk(child.path, info, ancestors)
+			if child.err != nil {
+				r.fail(child.err)
+			}
+		}(child, childInfo)
+	}
+	wg.Wait()
+
+	if stop != nil {
+		return stats, stop
+	}
+	if concurrent && r.failErr != nil {
+		return stats, r.failErr
+	}
+
+	for _, child := range children {
+		if child.err != nil {
+			errs.add(child.err)
+		}
+		stats.merge(child.stats)
+	}
+	if r.o.timing {
+		// Waiting for the children to be delivered is not part of it.
+		stats.elapsed = time.Since(start)
+	}
+
+	for _, child := range children {
+		if r.ctx.Err() != nil {
+			// Nobody is waiting for the results of a stopped scan.
+			break
+		}
+		if r.emit != nil && (r.m == nil || r.m.Match(child.path, child.entry)) {
+			if r.o.maxDepth <= 0 || depthBelow(r.scanRoot, child.path) <= r.o.maxDepth {
+				r.emit(child.stats.info(child.path))
+			}
+		}
+	}
+
+	return stats, errs.err()
+}
+
+// ancestor is a directory above the one being measured by walk.
+type ancestor struct {
+	key  string // Identifies the directory, as returned by dirKey.
+	path string // Path the directory was reached through.
+}
+
+// subtree is a subdirectory being measured by walk.
+type subtree struct {
+	path  string
+	entry fs.DirEntry
+	stats dirStats
+	err   error
+}
+
+// dirKey returns a key identifying the directory at path, whose metadata is
+// info, independently of the path it is reached through.
+func (r *rollup) dirKey(path string, info fs.FileInfo) string {
+	if st, ok := statOf(info); ok {
+		return strconv.FormatUint(st.dev, 10) + ":" + strconv.FormatUint(st.ino, 10)
+	}
+	if r.o.fsys != nil {
+		return path
+	}
+	if realPath, err := filepath.EvalSymlinks(path); err == nil {
+		return realPath
+	}
+	return path
+}
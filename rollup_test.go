@@ -0,0 +1,128 @@
+package go_walk
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sort"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/akshaybabloo/go-walk/testfs"
+	"github.com/stretchr/testify/assert"
+)
+
+// nestedTree returns a tree of several projects, each with nested
+// node_modules directories and files of different sizes.
+func nestedTree() fstest.MapFS {
+	tree := fstest.MapFS{}
+	for i := 0; i < 6; i++ {
+		tree[fmt.Sprintf("project%d/src/main.go", i)] = &fstest.MapFile{Data: make([]byte, 10*i+1)}
+		for j := 0; j < 4; j++ {
+			pkg := fmt.Sprintf("project%d/node_modules/pkg%d", i, j)
+			tree[pkg+"/index.js"] = &fstest.MapFile{Data: make([]byte, 100+j)}
+			tree[pkg+"/node_modules/dep/index.js"] = &fstest.MapFile{Data: make([]byte, 7)}
+		}
+	}
+	return tree
+}
+
+// rollupOf measures root of fsys with a rollup measuring up to n
+// subdirectories of root at once, and returns the result, the nested
+// directories emitted sorted by path and the error.
+func rollupOf(fsys fs.FS, root string, n int, opts ...Option) (DirectoryInfo, []DirectoryInfo, error) {
+	o := newOptions(opts...)
+	o.fsys = fsys
+	m, err := o.matches()
+	if err != nil {
+		return DirectoryInfo{}, nil, err
+	}
+
+	var mu sync.Mutex
+	var emitted []DirectoryInfo
+	r, err := newRollup(context.Background(), root, root, o, m, func(dir DirectoryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		dir.ComputedAt = time.Time{}
+		emitted = append(emitted, dir)
+	})
+	if err != nil {
+		return DirectoryInfo{}, nil, err
+	}
+	r.concurrently(n)
+
+	dir, _, err := r.run(root)
+	dir.ComputedAt = time.Time{}
+	sort.Slice(emitted, func(i, j int) bool { return emitted[i].Path < emitted[j].Path })
+	return dir, emitted, err
+}
+
+func TestRollupConcurrently(t *testing.T) {
+	fsys := testfs.New(nestedTree())
+
+	sequential, sequentialEmitted, err := rollupOf(fsys, ".", 1, WithKeywords("node_modules"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2760), sequential.Size)
+	assert.Equal(t, 54, sequential.NumberOfFiles)
+	assert.Len(t, sequentialEmitted, 6+6*4)
+
+	// Measuring the subdirectories of the root at once gives the same sizes,
+	// counts and nested directories
+	concurrent, concurrentEmitted, err := rollupOf(fsys, ".", 8, WithKeywords("node_modules"))
+	assert.NoError(t, err)
+	assert.Equal(t, sequential.Size, concurrent.Size)
+	assert.Equal(t, sequential.SizeOnDisk, concurrent.SizeOnDisk)
+	assert.Equal(t, sequential.NumberOfFiles, concurrent.NumberOfFiles)
+	assert.Equal(t, sequential.NumberOfSubdirs, concurrent.NumberOfSubdirs)
+	assert.Equal(t, sequentialEmitted, concurrentEmitted)
+}
+
+func TestRollupConcurrentlyWithErrors(t *testing.T) {
+	fsys := testfs.New(nestedTree())
+	fsys.Deny("project1/node_modules/pkg2")
+	fsys.Deny("project4/src")
+
+	sequential, _, err := rollupOf(fsys, ".", 1)
+	assert.Len(t, err, 2)
+
+	// Under SkipAndCollect every error is collected alongside what could be
+	// measured, however the work was split up
+	concurrent, _, err := rollupOf(fsys, ".", 8)
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	var errs ErrorList
+	if assert.ErrorAs(t, err, &errs) {
+		assert.Len(t, errs, 2)
+	}
+	assert.Equal(t, sequential.Size, concurrent.Size)
+	assert.Equal(t, sequential.NumberOfFiles, concurrent.NumberOfFiles)
+	assert.Equal(t, 2, concurrent.SkippedEntries)
+	assert.True(t, concurrent.Incomplete)
+
+	// Under FailFast the first error stops the other subdirectories and
+	// nothing is measured
+	concurrent, _, err = rollupOf(fsys, ".", 8, WithErrorPolicy(FailFast))
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	assert.Zero(t, concurrent.Size)
+}
+
+func TestListDirStatFSReadsDirectoriesOnce(t *testing.T) {
+	fsys := testfs.New(nestedTree())
+
+	for _, opts := range [][]Option{
+		nil,
+		{WithKeywords("node_modules")},
+		{WithKeywords("node_modules"), WithWorkers(1)},
+	} {
+		fsys.Reset()
+		_, err := ListDirStatFS(fsys, ".", opts...)
+		assert.NoError(t, err)
+
+		reads := fsys.Reads()
+		assert.Len(t, reads, 1+6*(3+4*3))
+		for name, n := range reads {
+			assert.Equal(t, 1, n, name)
+		}
+	}
+}
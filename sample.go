@@ -0,0 +1,135 @@
+package go_walk
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+)
+
+// minSampleUnits is the number of subtrees EstimateSize splits a tree into,
+// where the tree is large enough, before sampling them.
+const minSampleUnits = 100
+
+// SizeEstimate is the extrapolated size of a directory tree.
+type SizeEstimate struct {
+	Size    int64 `json:"size" yaml:"size"`       // Estimated size of the tree in bytes.
+	Lower   int64 `json:"lower" yaml:"lower"`     // Lower bound of the 95% confidence interval.
+	Upper   int64 `json:"upper" yaml:"upper"`     // Upper bound of the 95% confidence interval.
+	Sampled int   `json:"sampled" yaml:"sampled"` // Number of subtrees measured.
+	Units   int   `json:"units" yaml:"units"`     // Number of subtrees the tree was split into.
+}
+
+// Exact reports whether every subtree was measured, so that Size is exact.
+func (e SizeEstimate) Exact() bool {
+	return e.Sampled == e.Units
+}
+
+// EstimateSize estimates the size of the tree rooted at root by measuring a
+// random fraction of its subtrees, between 0 and 1, and extrapolating from
+// them. The tree is split into subtrees by descending level by level until
+// there are at least 100 of them; files above that level are counted
+// exactly. The bounds assume the sizes of the subtrees are roughly normal on
+// average, so they are more reliable the more subtrees are sampled. Symbolic
+// links are not counted, as with DirStat. Returns aggregated errors alongside
+// the estimate if parts of the tree could not be read.
+func EstimateSize(root string, fraction float64) (SizeEstimate, error) {
+	if fraction <= 0 || fraction > 1 {
+		return SizeEstimate{}, errors.New("the sampling fraction must be within (0, 1]")
+	}
+
+	pathStat, err := os.Stat(root)
+	if err != nil {
+		return SizeEstimate{}, err
+	}
+	if !pathStat.IsDir() {
+		return SizeEstimate{}, errors.New("the path provided is not a directory")
+	}
+
+	// Entries that cannot be read while splitting are left out, as they are
+	// when measuring the subtrees.
+	var errs ErrorList
+	var exact int64
+	units := []string{root}
+	for len(units) < minSampleUnits {
+		var next []string
+		for _, dir := range units {
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				errs.add(err)
+			}
+			for _, entry := range entries {
+				if entry.IsDir() {
+					next = append(next, filepath.Join(dir, entry.Name()))
+					continue
+				}
+				if entry.Type()&fs.ModeSymlink != 0 {
+					// Symbolic links are not counted, as with DirStat.
+					continue
+				}
+				info, err := entry.Info()
+				if err != nil {
+					errs.add(err)
+					continue
+				}
+				exact += info.Size()
+			}
+		}
+		units = next
+		if len(units) == 0 {
+			return SizeEstimate{Size: exact, Lower: exact, Upper: exact}, errs.err()
+		}
+	}
+
+	n := int(math.Ceil(fraction * float64(len(units))))
+	if n < 2 && len(units) >= 2 {
+		// At least two samples are needed to estimate the variance.
+		n = 2
+	}
+
+	var sizes []float64
+	var measured int64
+	for _, i := range rand.Perm(len(units))[:n] {
+		dirStat, err := calculateDirStats(context.Background(), units[i], newOptions())
+		if err != nil {
+			errs.add(err)
+		}
+		sizes = append(sizes, float64(dirStat.Size))
+		measured += dirStat.Size
+	}
+
+	var mean float64
+	for _, size := range sizes {
+		mean += size
+	}
+	mean /= float64(n)
+
+	var variance float64
+	if n > 1 {
+		for _, size := range sizes {
+			variance += (size - mean) * (size - mean)
+		}
+		variance /= float64(n - 1)
+	}
+
+	N := float64(len(units))
+	total := float64(exact) + N*mean
+	// Standard error of the total with the finite population correction.
+	margin := 1.96 * N * math.Sqrt(variance/float64(n)*(1-float64(n)/N))
+
+	estimate := SizeEstimate{
+		Size:    int64(math.Round(total)),
+		Lower:   int64(math.Round(total - margin)),
+		Upper:   int64(math.Round(total + margin)),
+		Sampled: n,
+		Units:   len(units),
+	}
+	// The tree holds at least what was actually measured.
+	if minimum := exact + measured; estimate.Lower < minimum {
+		estimate.Lower = minimum
+	}
+	return estimate, errs.err()
+}
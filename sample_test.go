@@ -0,0 +1,102 @@
+package go_walk
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akshaybabloo/go-walk/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateSize(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-estimate-size-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	// 10 projects with 20 packages each, one 100 byte file in every package,
+	// and one top-level file
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 20; j++ {
+			dir := filepath.Join(tmpDir, fmt.Sprintf("project%d", i), fmt.Sprintf("package%d", j))
+			err = os.MkdirAll(dir, 0755)
+			assert.NoError(t, err)
+			err = os.WriteFile(filepath.Join(dir, "test.txt"), make([]byte, 100), 0644)
+			assert.NoError(t, err)
+		}
+	}
+	err = os.WriteFile(filepath.Join(tmpDir, "README.md"), make([]byte, 50), 0644)
+	assert.NoError(t, err)
+
+	estimate, err := EstimateSize(tmpDir, 0.1)
+	assert.NoError(t, err)
+	assert.Equal(t, SizeEstimate{Size: 20050, Lower: 20050, Upper: 20050, Sampled: 20, Units: 200}, estimate)
+	assert.False(t, estimate.Exact())
+
+	estimate, err = EstimateSize(tmpDir, 1)
+	assert.NoError(t, err)
+	assert.True(t, estimate.Exact())
+	assert.Equal(t, int64(20050), estimate.Size)
+
+	_, err = EstimateSize(tmpDir, 0)
+	assert.Error(t, err)
+}
+
+func TestEstimateSizeWithUnreadableDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for the superuser")
+	}
+
+	tree := fixtures.Tree{"project0/private/": {Mode: 0200}}
+	for i := 0; i < minSampleUnits; i++ {
+		tree[fmt.Sprintf("project%d/test.txt", i)] = fixtures.File(10)
+	}
+	tmpDir := fixtures.Build(t, tree)
+
+	// The estimate is made from what could be measured
+	estimate, err := EstimateSize(tmpDir, 1)
+	assert.ErrorIs(t, err, os.ErrPermission)
+	assert.True(t, estimate.Exact())
+	assert.Equal(t, int64(10*minSampleUnits), estimate.Size)
+}
+
+func TestEstimateSizeWithSymlinks(t *testing.T) {
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"root.txt":    fixtures.File(10),
+		"link.txt":    fixtures.Symlink("root.txt"),
+		"a/test.txt":  fixtures.File(100),
+		"a/link.txt":  fixtures.Symlink("test.txt"),
+		"a/b/nested":  fixtures.Symlink("../test.txt"),
+		"a/b/file.js": fixtures.File(1000),
+	})
+
+	// Links are skipped wherever they are, as DirSize skips them
+	estimate, err := EstimateSize(tmpDir, 1)
+	assert.NoError(t, err)
+	size, err := DirSize(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1110), estimate.Size)
+	assert.Equal(t, size, estimate.Size)
+}
+
+func TestEstimateSizeWithUnreadableDirectoryAboveSplit(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for the superuser")
+	}
+
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"root.txt":   fixtures.File(10),
+		"a/test.txt": fixtures.File(100),
+		"private/":   {Mode: 0200},
+	})
+
+	// Splitting carries on past the unreadable directory
+	estimate, err := EstimateSize(tmpDir, 1)
+	assert.ErrorIs(t, err, os.ErrPermission)
+	assert.Equal(t, int64(110), estimate.Size)
+}
@@ -0,0 +1,115 @@
+package go_walk
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// defaultWorkers is the number of directories whose stats are computed
+// concurrently unless WithWorkers says otherwise.
+const defaultWorkers = 8
+
+// SchedulingPolicy decides the order in which matched directories are
+// processed, set with WithScheduling.
+type SchedulingPolicy int
+
+const (
+	// Interleaved takes turns between the top-level subtrees of the scanned
+	// directory, so that results from the whole tree arrive early instead of
+	// one large subtree being finished before the others are started.
+	Interleaved SchedulingPolicy = iota
+	// InOrder processes directories in the order they are found.
+	InOrder
+)
+
+// workQueue hands out directory paths to workers according to a
+// SchedulingPolicy. Paths are grouped by the top-level subtree they belong
+// to and, for Interleaved, the groups take turns.
+type workQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	policy SchedulingPolicy
+	groups map[string][]string
+	order  []string
+	next   int
+	closed bool
+}
+
+// newWorkQueue returns an empty workQueue using policy.
+func newWorkQueue(policy SchedulingPolicy) *workQueue {
+	q := &workQueue{
+		policy: policy,
+		groups: make(map[string][]string),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push queues path, which belongs to the top-level subtree group.
+func (q *workQueue) push(group, path string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.policy == InOrder {
+		group = ""
+	}
+
+	if _, exists := q.groups[group]; !exists {
+		q.order = append(q.order, group)
+	}
+	q.groups[group] = append(q.groups[group], path)
+	q.cond.Signal()
+}
+
+// close marks the end of the work. Queued paths are still handed out.
+func (q *workQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+// pop waits for the next path to process. It returns false once the queue is
+// closed and empty.
+func (q *workQueue) pop() (string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.order) == 0 {
+		if q.closed {
+			return "", false
+		}
+		q.cond.Wait()
+	}
+
+	if q.next >= len(q.order) {
+		q.next = 0
+	}
+
+	group := q.order[q.next]
+	paths := q.groups[group]
+	path := paths[0]
+
+	if len(paths) == 1 {
+		// Drop the exhausted group; the next group moves into its place.
+		delete(q.groups, group)
+		q.order = append(q.order[:q.next], q.order[q.next+1:]...)
+	} else {
+		q.groups[group] = paths[1:]
+		q.next++
+	}
+
+	return path, true
+}
+
+// topLevelGroup returns the name of the top-level subtree of root that path
+// belongs to, or an empty string for root itself.
+func topLevelGroup(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return ""
+	}
+	group, _, _ := strings.Cut(rel, string(filepath.Separator))
+	return group
+}
@@ -0,0 +1,65 @@
+package go_walk
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/akshaybabloo/go-walk/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkQueue(t *testing.T) {
+	root := filepath.Join("tmp", "root")
+	paths := []string{
+		filepath.Join(root, "a", "1"),
+		filepath.Join(root, "a", "2"),
+		filepath.Join(root, "a", "3"),
+		filepath.Join(root, "b", "1"),
+		filepath.Join(root, "c"),
+	}
+
+	drain := func(policy SchedulingPolicy) []string {
+		queue := newWorkQueue(policy)
+		for _, path := range paths {
+			queue.push(topLevelGroup(root, path), path)
+		}
+		queue.close()
+
+		var result []string
+		for p, ok := queue.pop(); ok; p, ok = queue.pop() {
+			result = append(result, p)
+		}
+		return result
+	}
+
+	assert.Equal(t, paths, drain(InOrder))
+	assert.Equal(t, []string{paths[0], paths[3], paths[4], paths[1], paths[2]}, drain(Interleaved))
+}
+
+func TestWithScheduling(t *testing.T) {
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"a/1/node_modules/index.js": fixtures.File(1),
+		"a/2/node_modules/index.js": fixtures.File(1),
+		"b/node_modules/index.js":   fixtures.File(1),
+	})
+
+	directories, err := ListDirStat(tmpDir, WithKeywords("node_modules"), WithWorkers(1), WithScheduling(InOrder))
+	assert.NoError(t, err)
+
+	var paths []string
+	for _, dir := range directories {
+		paths = append(paths, dir.Path)
+	}
+	assert.Equal(t, []string{
+		filepath.Join(tmpDir, "a", "1", "node_modules"),
+		filepath.Join(tmpDir, "a", "2", "node_modules"),
+		filepath.Join(tmpDir, "b", "node_modules"),
+	}, paths)
+}
+
+func TestTopLevelGroup(t *testing.T) {
+	root := filepath.Join("tmp", "root")
+	assert.Equal(t, "", topLevelGroup(root, root))
+	assert.Equal(t, "a", topLevelGroup(root, filepath.Join(root, "a")))
+	assert.Equal(t, "a", topLevelGroup(root, filepath.Join(root, "a", "b", "c")))
+}
@@ -0,0 +1,45 @@
+package go_walk
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// shadowStorageUnits maps the units vssadmin reports sizes in to bytes. Like
+// the rest of Windows it labels binary units with decimal names.
+var shadowStorageUnits = map[string]float64{
+	"B": 1, "KB": float64(KiB), "MB": float64(MiB), "GB": float64(GiB), "TB": float64(TiB), "PB": float64(PiB),
+}
+
+// parseShadowStorage parses the output of "vssadmin list shadowstorage" and
+// returns the space used by shadow copies in bytes, summed over every
+// association listed. Lines such as
+//
+//	Used Shadow Copy Storage space: 1.25 GB (2%)
+//
+// are recognised in English output only, where sizes may have commas
+// between thousands.
+func parseShadowStorage(output []byte) int64 {
+	var used int64
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		label, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || label != "Used Shadow Copy Storage space" {
+			continue
+		}
+
+		fields := strings.Fields(value)
+		if len(fields) < 2 {
+			continue
+		}
+		n, err := strconv.ParseFloat(strings.ReplaceAll(fields[0], ",", ""), 64)
+		unit, known := shadowStorageUnits[fields[1]]
+		if err != nil || !known {
+			continue
+		}
+		used += int64(n * unit)
+	}
+	return used
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package go_walk
+
+// shadowStorageSupported reports whether shadowStorage can find any on this
+// platform.
+const shadowStorageSupported = false
+
+// shadowStorage returns the space used by shadow copies on the volume mounted
+// at mount, which only exist on Windows.
+func shadowStorage(Mount) (int64, error) {
+	return 0, nil
+}
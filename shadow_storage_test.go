@@ -0,0 +1,30 @@
+package go_walk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseShadowStorage(t *testing.T) {
+	output := []byte(`vssadmin 1.1 - Volume Shadow Copy Service administrative command-line tool
+(C) Copyright 2001-2013 Microsoft Corp.
+
+Shadow Copy Storage association
+   For volume: (C:)\\?\Volume{8d5e1f3a-0000-0000-0000-100000000000}\
+   Shadow Copy Storage volume: (C:)\\?\Volume{8d5e1f3a-0000-0000-0000-100000000000}\
+   Used Shadow Copy Storage space: 1.5 GB (2%)
+   Allocated Shadow Copy Storage space: 2 GB (3%)
+   Maximum Shadow Copy Storage space: 10 GB (10%)
+
+Shadow Copy Storage association
+   For volume: (C:)\\?\Volume{8d5e1f3a-0000-0000-0000-100000000000}\
+   Shadow Copy Storage volume: (D:)\\?\Volume{8d5e1f3a-0000-0000-0000-200000000000}\
+   Used Shadow Copy Storage space: 512 MB (1%)
+`)
+
+	assert.Equal(t, int64(1<<30+1<<29+1<<29), parseShadowStorage(output))
+	assert.Equal(t, int64(1234.5*(1<<30)), parseShadowStorage([]byte("Used Shadow Copy Storage space: 1,234.5 GB (12%)")))
+	assert.Zero(t, parseShadowStorage([]byte("No items found that satisfy the query.")))
+	assert.Zero(t, parseShadowStorage(nil))
+}
@@ -0,0 +1,30 @@
+package go_walk
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// shadowStorageSupported reports whether shadowStorage can find any on this
+// platform.
+const shadowStorageSupported = true
+
+// shadowStorage returns the space used by the Volume Shadow Copy Service on
+// the volume mounted at mount, such as for System Restore points. Querying it
+// requires administrative privileges, without which zero is returned.
+func shadowStorage(mount Mount) (int64, error) {
+	if !isPrivileged() || mount.Remote {
+		return 0, nil
+	}
+
+	volume := strings.TrimSuffix(mount.Path, `\`)
+	output, err := exec.Command("vssadmin", "list", "shadowstorage", "/for="+volume).Output()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return 0, err
+	}
+	// vssadmin also exits with an error when the volume has no shadow
+	// storage, which leaves nothing to parse.
+	return parseShadowStorage(output), nil
+}
@@ -0,0 +1,100 @@
+package go_walk
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Decimal, SI, sizes in bytes, for use with options such as WithMinSize.
+const (
+	KB int64 = 1000
+	MB       = 1000 * KB
+	GB       = 1000 * MB
+	TB       = 1000 * GB
+	PB       = 1000 * TB
+)
+
+// Binary, IEC, sizes in bytes.
+const (
+	KiB int64 = 1 << (10 * (iota + 1))
+	MiB
+	GiB
+	TiB
+	PiB
+)
+
+// FormatBytes returns size in binary units with one decimal, such as
+// "1.5 GiB", or in bytes below a KiB, such as "512 B".
+func FormatBytes(size int64) string {
+	return formatBytes(size, 1024, []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"})
+}
+
+// FormatBytesSI is like FormatBytes but uses decimal units, such as "1.6 GB",
+// as disk vendors and macOS do.
+func FormatBytesSI(size int64) string {
+	return formatBytes(size, 1000, []string{"kB", "MB", "GB", "TB", "PB", "EB"})
+}
+
+// formatBytes returns size in the largest of units, each base times the one
+// before it starting from base bytes, that it amounts to at least one of once
+// rounded to one decimal, so that sizes just below a unit are not shown as
+// 1024.0 of the one before it.
+func formatBytes(size int64, base float64, units []string) string {
+	value := math.Abs(float64(size))
+	if value < base {
+		return strconv.FormatInt(size, 10) + " B"
+	}
+
+	unit := -1
+	for value >= base && unit < len(units)-1 {
+		value /= base
+		unit++
+	}
+	if value = math.Round(value*10) / 10; value >= base && unit < len(units)-1 {
+		value /= base
+		unit++
+	}
+	if size < 0 {
+		value = -value
+	}
+	return strconv.FormatFloat(value, 'f', 1, 64) + " " + units[unit]
+}
+
+// sizeUnits maps the units ParseSize accepts, in lower case, to their size in
+// bytes. Single letters are binary, as in the output of du -h or ls -h.
+var sizeUnits = map[string]int64{
+	"": 1, "b": 1,
+	"k": KiB, "m": MiB, "g": GiB, "t": TiB, "p": PiB,
+	"kb": KB, "mb": MB, "gb": GB, "tb": TB, "pb": PB,
+	"kib": KiB, "mib": MiB, "gib": GiB, "tib": TiB, "pib": PiB,
+}
+
+// ParseSize parses a size such as "1.5GiB", "100 MB", "4k" or "512" into
+// bytes. Units are case-insensitive: KB, MB, GB, TB and PB are decimal, KiB,
+// MiB, GiB, TiB and PiB are binary, as are K, M, G, T and P, and a number
+// without a unit is in bytes. Negative sizes are rejected.
+func ParseSize(s string) (int64, error) {
+	trimmed := strings.TrimSpace(s)
+	number := strings.TrimRightFunc(trimmed, func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+	})
+	unit, ok := sizeUnits[strings.ToLower(trimmed[len(number):])]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit", s)
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	if value < 0 {
+		return 0, fmt.Errorf("invalid size %q: negative", s)
+	}
+	bytes := value * float64(unit)
+	if bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q: out of range", s)
+	}
+	return int64(math.Round(bytes)), nil
+}
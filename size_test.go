@@ -0,0 +1,65 @@
+package go_walk
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		size int64
+		iec  string
+		si   string
+	}{
+		{0, "0 B", "0 B"},
+		{512, "512 B", "512 B"},
+		{1000, "1000 B", "1.0 kB"},
+		{1536, "1.5 KiB", "1.5 kB"},
+		{3 * GiB / 2, "1.5 GiB", "1.6 GB"},
+		{-2 * MiB, "-2.0 MiB", "-2.1 MB"},
+		{1 << 62, "4.0 EiB", "4.6 EB"},
+		// Sizes just below a unit round up into it
+		{1023, "1023 B", "1.0 kB"},
+		{1024, "1.0 KiB", "1.0 kB"},
+		{1048524, "1023.9 KiB", "1.0 MB"},
+		{1048575, "1.0 MiB", "1.0 MB"},
+		{MiB, "1.0 MiB", "1.0 MB"},
+		{999949, "976.5 KiB", "999.9 kB"},
+		{999999, "976.6 KiB", "1.0 MB"},
+		{GiB - 1, "1.0 GiB", "1.1 GB"},
+		{-1048575, "-1.0 MiB", "-1.0 MB"},
+		{math.MaxInt64, "8.0 EiB", "9.2 EB"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.iec, FormatBytes(tt.size))
+		assert.Equal(t, tt.si, FormatBytesSI(tt.size))
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"512", 512},
+		{"512B", 512},
+		{"1.5GiB", 3 * GiB / 2},
+		{"100 MB", 100 * MB},
+		{"100mb", 100 * MB},
+		{"4k", 4 * KiB},
+		{" 2 TiB ", 2 * TiB},
+		{"1.5 kB", 1500},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.input)
+		assert.NoError(t, err, tt.input)
+		assert.Equal(t, tt.want, got, tt.input)
+	}
+
+	for _, input := range []string{"", "GiB", "1.5 XB", "abc", "1e30 PB", "NaN", "-5MB", "-1"} {
+		_, err := ParseSize(input)
+		assert.Error(t, err, input)
+	}
+}
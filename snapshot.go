@@ -0,0 +1,407 @@
+package go_walk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// snapshotMagic identifies the binary snapshot format.
+const snapshotMagic = "GWSN"
+
+// snapshotVersion is the version of the binary snapshot format. Version 2
+// added SizeOnDisk; snapshots of version 1 are still read, without it.
+const snapshotVersion = 2
+
+// ErrCorruptSnapshot is returned when a snapshot cannot be decoded or fails
+// its checksum.
+var ErrCorruptSnapshot = errors.New("the snapshot is corrupt")
+
+// Snapshot is a saved scan result.
+type Snapshot struct {
+	Host        string          `json:"host" yaml:"host"`               // Name of the machine that was scanned.
+	Root        string          `json:"root" yaml:"root"`               // Directory that was scanned.
+	CreatedAt   time.Time       `json:"created_at" yaml:"created_at"`   // When the scan was taken.
+	Directories []DirectoryInfo `json:"directories" yaml:"directories"` // Scanned directories, sorted by path with each directory followed by everything below it.
+}
+
+// NewSnapshot returns a Snapshot of directories, as returned by a scan of
+// root on this machine, taken now.
+func NewSnapshot(root string, directories []DirectoryInfo) *Snapshot {
+	sorted := cloneDirectories(directories)
+	sort.Slice(sorted, func(i, j int) bool {
+		return pathLess(sorted[i].Path, sorted[j].Path)
+	})
+
+	host, _ := os.Hostname()
+
+	return &Snapshot{
+		Host:        host,
+		Root:        root,
+		CreatedAt:   time.Now(),
+		Directories: sorted,
+	}
+}
+
+// WriteTo writes the snapshot to w in a compact binary format: sizes and
+// counts are varints, every path only stores what differs from the previous
+// one, and the whole snapshot is protected by a CRC-32 checksum. It stores the
+// path, sizes, times and counts of every directory; the other fields of
+// DirectoryInfo are not kept.
+func (s *Snapshot) WriteTo(w io.Writer) (int64, error) {
+	var buf []byte
+	buf = append(buf, snapshotMagic...)
+	buf = append(buf, snapshotVersion)
+	buf = appendString(buf, s.Host)
+	buf = appendString(buf, s.Root)
+	buf = appendTime(buf, s.CreatedAt)
+	buf = binary.AppendUvarint(buf, uint64(len(s.Directories)))
+
+	var previous string
+	for _, dir := range s.Directories {
+		shared := commonPrefixLen(previous, dir.Path)
+		buf = binary.AppendUvarint(buf, uint64(shared))
+		buf = appendString(buf, dir.Path[shared:])
+		buf = binary.AppendVarint(buf, dir.Size)
+		buf = binary.AppendVarint(buf, dir.SizeOnDisk)
+		buf = appendTime(buf, dir.CreationTime)
+		buf = appendTime(buf, dir.LastModified)
+		buf = binary.AppendUvarint(buf, uint64(dir.NumberOfFiles))
+		buf = binary.AppendUvarint(buf, uint64(dir.NumberOfSubdirs))
+		previous = dir.Path
+	}
+
+	buf = binary.LittleEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// ReadSnapshot reads a snapshot written by Snapshot.WriteTo from r.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSnapshot(data)
+}
+
+// Save writes the snapshot to the file at path atomically: it is written to
+// a temporary file next to path, flushed to disk and then renamed over path,
+// so a crash leaves either the previous snapshot or the new one in place,
+// never a mix of both. RecoverSnapshot cleans up after such a crash.
+func (s *Snapshot) Save(path string) error {
+	var buf bytes.Buffer
+	if _, err := s.WriteTo(&buf); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// writeFileAtomic replaces the file at path with data the way Save describes.
+func writeFileAtomic(path string, data []byte) error {
+	dir, name := filepath.Split(path)
+	tmp, err := os.CreateTemp(dir, name+snapshotTempSuffix)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if tmp != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	tmp = nil
+
+	return syncDir(dir)
+}
+
+// snapshotTempSuffix is appended to the name of a snapshot, followed by a
+// random string, to name the temporary file Save writes it to.
+const snapshotTempSuffix = ".tmp-*"
+
+// syncDir flushes the directory entries of dir to disk, so that a rename
+// within it survives a crash. Windows cannot open directories for this, and
+// makes renames durable by itself.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	if dir == "" {
+		dir = "."
+	}
+
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
+
+// RecoverSnapshot loads the snapshot saved in the file at path after Save may
+// have been interrupted, for example by a crash or power loss. Temporary files
+// left behind by Save are removed, except that a complete one newer than path,
+// or replacing a path that is missing or corrupt, is moved into place first.
+// Returns ErrCorruptSnapshot if no intact snapshot is left and os.ErrNotExist
+// if there was none.
+func RecoverSnapshot(path string) (*Snapshot, error) {
+	return recoverSnapshot(path, LoadSnapshot)
+}
+
+// recoverSnapshot implements RecoverSnapshot with load reading snapshots.
+func recoverSnapshot(path string, load func(string) (*Snapshot, error)) (*Snapshot, error) {
+	leftovers, err := filepath.Glob(globEscape(path) + snapshotTempSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, loadErr := load(path)
+	for _, leftover := range leftovers {
+		candidate, err := load(leftover)
+		if err == nil && (snapshot == nil || candidate.CreatedAt.After(snapshot.CreatedAt)) {
+			if err := os.Rename(leftover, path); err != nil {
+				return nil, err
+			}
+			snapshot, loadErr = candidate, nil
+			continue
+		}
+		if err := os.Remove(leftover); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	if len(leftovers) > 0 {
+		if err := syncDir(filepath.Dir(path)); err != nil {
+			return nil, err
+		}
+	}
+	return snapshot, nil
+}
+
+// globEscape escapes the characters of path that filepath.Glob would treat
+// as a pattern.
+func globEscape(path string) string {
+	if runtime.GOOS == "windows" {
+		// Backslashes separate paths on Windows and cannot escape.
+		return strings.NewReplacer("[", "[[]", "*", "[*]", "?", "[?]").Replace(path)
+	}
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "*", `\*`, "?", `\?`).Replace(path)
+}
+
+// LoadSnapshot reads the snapshot saved in the file at path.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSnapshot(data)
+}
+
+// decodeSnapshot decodes a snapshot in the binary format.
+func decodeSnapshot(data []byte) (*Snapshot, error) {
+	d, err := newSnapshotDecoder(data)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{
+		Host:      d.host,
+		Root:      d.root,
+		CreatedAt: d.createdAt,
+	}
+	if d.count > uint64(len(data)) {
+		return nil, ErrCorruptSnapshot
+	}
+	s.Directories = make([]DirectoryInfo, 0, d.count)
+
+	for dir, ok := d.next(); ok; dir, ok = d.next() {
+		s.Directories = append(s.Directories, dir)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return s, nil
+}
+
+// snapshotDecoder reads the binary snapshot format from a byte slice.
+type snapshotDecoder struct {
+	data      []byte
+	off       int
+	err       error
+	version   byte
+	host      string
+	root      string
+	createdAt time.Time
+	count     uint64
+	read      uint64
+	path      []byte
+}
+
+// newSnapshotDecoder verifies data and decodes its header. The directories
+// are then decoded one at a time with next.
+func newSnapshotDecoder(data []byte) (*snapshotDecoder, error) {
+	if bytes.HasPrefix(data, []byte(encryptedSnapshotMagic)) {
+		return nil, ErrEncryptedSnapshot
+	}
+	if len(data) < len(snapshotMagic)+1+4 || string(data[:len(snapshotMagic)]) != snapshotMagic {
+		return nil, ErrCorruptSnapshot
+	}
+	version := data[len(snapshotMagic)]
+	if version < 1 || version > snapshotVersion {
+		return nil, errors.New("unsupported snapshot version")
+	}
+
+	body := data[:len(data)-4]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(data[len(body):]) {
+		return nil, ErrCorruptSnapshot
+	}
+
+	d := &snapshotDecoder{data: body, off: len(snapshotMagic) + 1, version: version}
+	d.host = string(d.bytes())
+	d.root = string(d.bytes())
+	d.createdAt = d.time()
+	d.count = d.uvarint()
+	if d.err != nil {
+		return nil, d.err
+	}
+	return d, nil
+}
+
+// next decodes the next directory. It returns false when all directories
+// have been decoded or an error occurred, which is then held in d.err.
+func (d *snapshotDecoder) next() (DirectoryInfo, bool) {
+	if d.err != nil || d.read == d.count {
+		return DirectoryInfo{}, false
+	}
+
+	shared := d.uvarint()
+	suffix := d.bytes()
+	if d.err == nil && shared > uint64(len(d.path)) {
+		d.err = ErrCorruptSnapshot
+	}
+	if d.err != nil {
+		return DirectoryInfo{}, false
+	}
+	d.path = append(d.path[:shared], suffix...)
+
+	dir := DirectoryInfo{Path: string(d.path), Size: d.varint()}
+	if d.version >= 2 {
+		dir.SizeOnDisk = d.varint()
+	}
+	dir.CreationTime = d.time()
+	dir.LastModified = d.time()
+	dir.NumberOfFiles = int(d.uvarint())
+	dir.NumberOfSubdirs = int(d.uvarint())
+	if d.err != nil {
+		return DirectoryInfo{}, false
+	}
+
+	d.read++
+	return dir, true
+}
+
+// uvarint decodes an unsigned varint.
+func (d *snapshotDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data[d.off:])
+	if n <= 0 {
+		d.err = ErrCorruptSnapshot
+		return 0
+	}
+	d.off += n
+	return v
+}
+
+// varint decodes a signed varint.
+func (d *snapshotDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.data[d.off:])
+	if n <= 0 {
+		d.err = ErrCorruptSnapshot
+		return 0
+	}
+	d.off += n
+	return v
+}
+
+// bytes decodes a length prefixed byte string. The result aliases the
+// decoded data.
+func (d *snapshotDecoder) bytes() []byte {
+	n := d.uvarint()
+	if d.err != nil {
+		return nil
+	}
+	if n > uint64(len(d.data)-d.off) {
+		d.err = ErrCorruptSnapshot
+		return nil
+	}
+	b := d.data[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b
+}
+
+// time decodes a time written by appendTime.
+func (d *snapshotDecoder) time() time.Time {
+	nanos := d.varint()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// appendString appends s to buf, prefixed with its length.
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// appendTime appends t to buf as nanoseconds since the Unix epoch, or zero for
+// the zero time.
+func appendTime(buf []byte, t time.Time) []byte {
+	if t.IsZero() {
+		return binary.AppendVarint(buf, 0)
+	}
+	return binary.AppendVarint(buf, t.UnixNano())
+}
+
+// commonPrefixLen returns the length of the longest common prefix of a and b.
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,98 @@
+package go_walk
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// encryptedSnapshotMagic identifies the encrypted snapshot format.
+const encryptedSnapshotMagic = "GWSE"
+
+// encryptedSnapshotVersion is the version of the encrypted snapshot format.
+const encryptedSnapshotVersion = 1
+
+// ErrEncryptedSnapshot is returned when an encrypted snapshot is read without
+// a key.
+var ErrEncryptedSnapshot = errors.New("the snapshot is encrypted")
+
+// SaveEncrypted writes the snapshot to the file at path like Save, encrypted
+// with AES-GCM under key, which must be 16, 24 or 32 bytes long to select
+// AES-128, AES-192 or AES-256. Paths and host names cannot be read from the
+// file without the key, and any tampering with it is detected.
+func (s *Snapshot) SaveEncrypted(path string, key []byte) error {
+	gcm, err := snapshotCipher(key)
+	if err != nil {
+		return err
+	}
+
+	var plain bytes.Buffer
+	if _, err := s.WriteTo(&plain); err != nil {
+		return err
+	}
+
+	header := append([]byte(encryptedSnapshotMagic), encryptedSnapshotVersion)
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	// Sealing appends to the output, which must not share the header
+	// passed as additional data.
+	out := make([]byte, 0, len(header)+len(nonce)+plain.Len()+gcm.Overhead())
+	out = append(out, header...)
+	out = append(out, nonce...)
+	out = gcm.Seal(out, nonce, plain.Bytes(), header)
+	return writeFileAtomic(path, out)
+}
+
+// LoadEncryptedSnapshot reads the snapshot saved in the file at path by
+// SaveEncrypted with key. Returns ErrCorruptSnapshot if the file is damaged
+// or key is not the one it was saved with.
+func LoadEncryptedSnapshot(path string, key []byte) (*Snapshot, error) {
+	gcm, err := snapshotCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	headerLen := len(encryptedSnapshotMagic) + 1
+	if len(data) < headerLen+gcm.NonceSize() || string(data[:len(encryptedSnapshotMagic)]) != encryptedSnapshotMagic {
+		return nil, ErrCorruptSnapshot
+	}
+	if data[len(encryptedSnapshotMagic)] != encryptedSnapshotVersion {
+		return nil, errors.New("unsupported encrypted snapshot version")
+	}
+
+	header, nonce := data[:headerLen], data[headerLen:headerLen+gcm.NonceSize()]
+	plain, err := gcm.Open(nil, nonce, data[headerLen+gcm.NonceSize():], header)
+	if err != nil {
+		return nil, fmt.Errorf("%w or the key is wrong", ErrCorruptSnapshot)
+	}
+	return decodeSnapshot(plain)
+}
+
+// RecoverEncryptedSnapshot is RecoverSnapshot for snapshots saved by
+// SaveEncrypted with key.
+func RecoverEncryptedSnapshot(path string, key []byte) (*Snapshot, error) {
+	return recoverSnapshot(path, func(path string) (*Snapshot, error) {
+		return LoadEncryptedSnapshot(path, key)
+	})
+}
+
+// snapshotCipher returns the AES-GCM cipher for key.
+func snapshotCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,59 @@
+package go_walk
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotSaveLoadEncrypted(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test-snapshot-crypt-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	path := filepath.Join(tmpDir, "scan.snapshot")
+	key := bytes.Repeat([]byte{7}, 32)
+	snapshot := testSnapshot()
+
+	err = snapshot.SaveEncrypted(path, key)
+	assert.NoError(t, err)
+
+	// Paths cannot be read from the file
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "node_modules")
+
+	loaded, err := LoadEncryptedSnapshot(path, key)
+	assert.NoError(t, err)
+	assert.Equal(t, snapshot.Root, loaded.Root)
+	assertSameDirectories(t, snapshot.Directories, loaded.Directories)
+
+	recovered, err := RecoverEncryptedSnapshot(path, key)
+	assert.NoError(t, err)
+	assertSameDirectories(t, snapshot.Directories, recovered.Directories)
+
+	_, err = LoadEncryptedSnapshot(path, bytes.Repeat([]byte{8}, 32))
+	assert.ErrorIs(t, err, ErrCorruptSnapshot)
+
+	_, err = LoadEncryptedSnapshot(path, []byte("short"))
+	assert.Error(t, err)
+
+	_, err = LoadSnapshot(path)
+	assert.ErrorIs(t, err, ErrEncryptedSnapshot)
+
+	_, err = OpenSnapshot(path)
+	assert.ErrorIs(t, err, ErrEncryptedSnapshot)
+
+	// Any damage is detected
+	data[len(data)/2] ^= 0xff
+	err = os.WriteFile(path, data, 0644)
+	assert.NoError(t, err)
+	_, err = LoadEncryptedSnapshot(path, key)
+	assert.ErrorIs(t, err, ErrCorruptSnapshot)
+}
@@ -0,0 +1,45 @@
+package go_walk
+
+import "sort"
+
+// MergeSnapshots combines snapshots, typically taken on different machines,
+// into one. Every path is namespaced with the host it was scanned on, as in
+// "build-01:/var/lib/docker", so the merged snapshot can be queried per host
+// or across the whole fleet. When several snapshots contain the same path of
+// the same host, the directory from the most recent snapshot is kept.
+func MergeSnapshots(snapshots ...*Snapshot) *Snapshot {
+	ordered := make([]*Snapshot, len(snapshots))
+	copy(ordered, snapshots)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].CreatedAt.After(ordered[j].CreatedAt)
+	})
+
+	merged := &Snapshot{}
+	seen := make(map[string]struct{})
+
+	for _, snapshot := range ordered {
+		if snapshot.CreatedAt.After(merged.CreatedAt) {
+			merged.CreatedAt = snapshot.CreatedAt
+		}
+
+		for _, dir := range snapshot.Directories {
+			dir.Path = HostPath(snapshot.Host, dir.Path)
+			if _, exists := seen[dir.Path]; exists {
+				continue
+			}
+			seen[dir.Path] = struct{}{}
+			merged.Directories = append(merged.Directories, dir)
+		}
+	}
+
+	sort.Slice(merged.Directories, func(i, j int) bool {
+		return pathLess(merged.Directories[i].Path, merged.Directories[j].Path)
+	})
+
+	return merged
+}
+
+// HostPath returns path namespaced with host, as used by MergeSnapshots.
+func HostPath(host, path string) string {
+	return host + ":" + path
+}
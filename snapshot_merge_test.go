@@ -0,0 +1,33 @@
+package go_walk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeSnapshots(t *testing.T) {
+	now := time.Now()
+
+	web := &Snapshot{Host: "web-01", Root: "/var", CreatedAt: now.Add(-time.Hour), Directories: []DirectoryInfo{
+		{Path: "/var/log", Size: 100},
+		{Path: "/var/cache", Size: 10},
+	}}
+	webLater := &Snapshot{Host: "web-01", Root: "/var", CreatedAt: now, Directories: []DirectoryInfo{
+		{Path: "/var/log", Size: 150},
+	}}
+	build := &Snapshot{Host: "build-01", Root: "/var", CreatedAt: now.Add(-2 * time.Hour), Directories: []DirectoryInfo{
+		{Path: "/var/log", Size: 1000},
+	}}
+
+	merged := MergeSnapshots(web, build, webLater)
+	assert.True(t, now.Equal(merged.CreatedAt))
+	assert.Equal(t, []DirectoryInfo{
+		{Path: "build-01:/var/log", Size: 1000},
+		{Path: "web-01:/var/cache", Size: 10},
+		{Path: "web-01:/var/log", Size: 150},
+	}, merged.Directories)
+
+	assert.Equal(t, int64(160), merged.Query(HostPath("web-01", "/var")).Size)
+}
@@ -0,0 +1,81 @@
+package go_walk
+
+import (
+	"sort"
+	"time"
+)
+
+// QueryResult holds the aggregated stats of the directories at or below a
+// path of a snapshot. Directories nested within another matching directory
+// are counted in Directories but not added to the totals again.
+type QueryResult struct {
+	Prefix          string    `json:"prefix" yaml:"prefix"`                       // Path the query was made for.
+	Directories     int       `json:"directories" yaml:"directories"`             // Number of directories at or below Prefix.
+	Size            int64     `json:"size" yaml:"size"`                           // Combined size of the directories in bytes.
+	NumberOfFiles   int       `json:"number_of_files" yaml:"number_of_files"`     // Combined number of files.
+	NumberOfSubdirs int       `json:"number_of_subdirs" yaml:"number_of_subdirs"` // Combined number of subdirectories.
+	LastModified    time.Time `json:"last_modified" yaml:"last_modified"`         // Latest modification of any of the directories.
+}
+
+// Query returns the aggregated stats of the directories at or below prefix.
+// prefix matches whole path components, so "/home/user" does not match
+// "/home/username".
+func (s *Snapshot) Query(prefix string) QueryResult {
+	q := newQuery(prefix)
+
+	start := sort.Search(len(s.Directories), func(i int) bool {
+		return !pathLess(s.Directories[i].Path, prefix)
+	})
+	for _, dir := range s.Directories[start:] {
+		if !q.add(dir) {
+			break
+		}
+	}
+
+	return q.result
+}
+
+// Query returns the aggregated stats of the directories at or below prefix,
+// like Snapshot.Query.
+func (r *SnapshotReader) Query(prefix string) (QueryResult, error) {
+	q := newQuery(prefix)
+	err := r.each(func(dir DirectoryInfo) bool {
+		return pathLess(dir.Path, prefix) || q.add(dir)
+	})
+	return q.result, err
+}
+
+// query accumulates the directories of a snapshot, in path order, into a
+// QueryResult.
+type query struct {
+	result QueryResult
+	outer  string
+}
+
+// newQuery returns a query for prefix.
+func newQuery(prefix string) *query {
+	return &query{result: QueryResult{Prefix: prefix}}
+}
+
+// add adds dir to the result. It returns false, without adding it, once dir
+// is past the directories at or below the prefix, which are contiguous in
+// the order of pathLess.
+func (q *query) add(dir DirectoryInfo) bool {
+	if !isWithin(dir.Path, q.result.Prefix) {
+		return false
+	}
+
+	q.result.Directories++
+	if dir.LastModified.After(q.result.LastModified) {
+		q.result.LastModified = dir.LastModified
+	}
+
+	if q.outer != "" && isWithin(dir.Path, q.outer) {
+		return true
+	}
+	q.outer = dir.Path
+	q.result.Size += dir.Size
+	q.result.NumberOfFiles += dir.NumberOfFiles
+	q.result.NumberOfSubdirs += dir.NumberOfSubdirs
+	return true
+}
@@ -0,0 +1,70 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotQuery(t *testing.T) {
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	snapshot := NewSnapshot("/home", []DirectoryInfo{
+		{Path: "/home/user/project1", Size: 20, NumberOfFiles: 3, NumberOfSubdirs: 2, LastModified: modified},
+		{Path: "/home/user/project1/node_modules", Size: 12, NumberOfFiles: 1, LastModified: modified.Add(time.Hour)},
+		{Path: "/home/user/project1-old", Size: 100, NumberOfFiles: 10},
+		{Path: "/home/user/project2", Size: 4, NumberOfFiles: 1, NumberOfSubdirs: 1},
+		{Path: "/home/username", Size: 1000},
+	})
+
+	result := snapshot.Query("/home/user/project1")
+	assert.Equal(t, QueryResult{
+		Prefix:          "/home/user/project1",
+		Directories:     2,
+		Size:            20,
+		NumberOfFiles:   3,
+		NumberOfSubdirs: 2,
+		LastModified:    modified.Add(time.Hour),
+	}, result)
+
+	result = snapshot.Query("/home/user")
+	assert.Equal(t, 4, result.Directories)
+	assert.Equal(t, int64(124), result.Size)
+
+	result = snapshot.Query("/nowhere")
+	assert.Zero(t, result.Directories)
+
+	// The mapped reader gives the same answers
+	tmpDir, err := os.MkdirTemp("", "test-snapshot-query-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	path := filepath.Join(tmpDir, "scan.snapshot")
+	err = snapshot.Save(path)
+	assert.NoError(t, err)
+
+	reader, err := OpenSnapshot(path)
+	assert.NoError(t, err)
+	defer reader.Close()
+
+	for _, prefix := range []string{"/home/user/project1", "/home/user", "/home", "/nowhere"} {
+		result, err := reader.Query(prefix)
+		assert.NoError(t, err)
+		want := snapshot.Query(prefix)
+		assert.Equal(t, want.Directories, result.Directories, prefix)
+		assert.Equal(t, want.Size, result.Size, prefix)
+	}
+}
+
+func TestPathLess(t *testing.T) {
+	a, ab, aDash := filepath.FromSlash("/a"), filepath.FromSlash("/a/b"), filepath.FromSlash("/a-b")
+	assert.True(t, pathLess(a, ab))
+	assert.True(t, pathLess(ab, aDash))
+	assert.False(t, pathLess(aDash, ab))
+	assert.False(t, pathLess(a, a))
+}
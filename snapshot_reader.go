@@ -0,0 +1,127 @@
+package go_walk
+
+import "container/heap"
+
+// SnapshotReader answers queries about a saved snapshot directly from the
+// memory-mapped file, without decoding it into a Snapshot first. Every query
+// streams through the file once. A SnapshotReader is safe for concurrent use
+// until it is closed.
+type SnapshotReader struct {
+	data  []byte
+	unmap func() error
+}
+
+// OpenSnapshot maps the snapshot saved in the file at path into memory and
+// verifies its checksum.
+func OpenSnapshot(path string) (*SnapshotReader, error) {
+	data, unmap, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := newSnapshotDecoder(data); err != nil {
+		_ = unmap()
+		return nil, err
+	}
+
+	return &SnapshotReader{data: data, unmap: unmap}, nil
+}
+
+// Close unmaps the snapshot. Results returned by earlier queries remain
+// valid.
+func (r *SnapshotReader) Close() error {
+	return r.unmap()
+}
+
+// Lookup returns the directory with the given path.
+func (r *SnapshotReader) Lookup(path string) (DirectoryInfo, bool, error) {
+	var found DirectoryInfo
+	var ok bool
+	err := r.each(func(dir DirectoryInfo) bool {
+		if dir.Path == path {
+			found, ok = dir, true
+			return false
+		}
+		// Directories are sorted by path, so it cannot come later.
+		return pathLess(dir.Path, path)
+	})
+	return found, ok, err
+}
+
+// TopN returns the n largest directories, largest first.
+func (r *SnapshotReader) TopN(n int) ([]DirectoryInfo, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	h := &sizeHeap{}
+	err := r.each(func(dir DirectoryInfo) bool {
+		h.offer(dir, n)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h.largestFirst(), nil
+}
+
+// Total returns the combined size of the directories at or below prefix,
+// without double counting nested ones.
+func (r *SnapshotReader) Total(prefix string) (int64, error) {
+	result, err := r.Query(prefix)
+	return result.Size, err
+}
+
+// each calls fn for every directory in the snapshot, in path order, until fn
+// returns false.
+func (r *SnapshotReader) each(fn func(dir DirectoryInfo) bool) error {
+	d, err := newSnapshotDecoder(r.data)
+	if err != nil {
+		return err
+	}
+
+	for dir, ok := d.next(); ok; dir, ok = d.next() {
+		if !fn(dir) {
+			return nil
+		}
+	}
+	return d.err
+}
+
+// sizeHeap is a min-heap of directories ordered by size.
+type sizeHeap []DirectoryInfo
+
+func (h sizeHeap) Len() int           { return len(h) }
+func (h sizeHeap) Less(i, j int) bool { return h[i].Size < h[j].Size }
+func (h sizeHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *sizeHeap) Push(x any) {
+	*h = append(*h, x.(DirectoryInfo))
+}
+
+func (h *sizeHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// offer adds dir to the heap if it is among the n largest directories
+// offered so far.
+func (h *sizeHeap) offer(dir DirectoryInfo, n int) {
+	if h.Len() < n {
+		heap.Push(h, dir)
+	} else if dir.Size > (*h)[0].Size {
+		(*h)[0] = dir
+		heap.Fix(h, 0)
+	}
+}
+
+// largestFirst returns the directories in the heap sorted by size, largest
+// first and then by path, leaving the heap invalid.
+func (h *sizeHeap) largestFirst() []DirectoryInfo {
+	result := []DirectoryInfo(*h)
+	sortDirectories(result, BySize, Descending)
+	return result
+}
@@ -0,0 +1,55 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotReader(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test-snapshot-reader-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	path := filepath.Join(tmpDir, "scan.snapshot")
+	err = testSnapshot().Save(path)
+	assert.NoError(t, err)
+
+	reader, err := OpenSnapshot(path)
+	assert.NoError(t, err)
+	defer reader.Close()
+
+	dir, ok, err := reader.Lookup("/home/user/project2/node_modules")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(4), dir.Size)
+
+	_, ok, err = reader.Lookup("/home/user/project3/node_modules")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	top, err := reader.TopN(2)
+	assert.NoError(t, err)
+	assert.Len(t, top, 2)
+	assert.Equal(t, "/home/user/project1/node_modules/package/node_modules", top[0].Path)
+	assert.Equal(t, "/home/user/project1/node_modules", top[1].Path)
+
+	// The nested node_modules is already part of its parent
+	total, err := reader.Total("/home/user/project1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), total)
+
+	total, err = reader.Total("/home/user")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(16), total)
+
+	err = os.WriteFile(path, []byte("not a snapshot"), 0644)
+	assert.NoError(t, err)
+	_, err = OpenSnapshot(path)
+	assert.ErrorIs(t, err, ErrCorruptSnapshot)
+}
@@ -0,0 +1,226 @@
+package go_walk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testSnapshot returns a snapshot of a few directories with known stats.
+func testSnapshot() *Snapshot {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return NewSnapshot("/home/user", []DirectoryInfo{
+		{Path: "/home/user/project2/node_modules", Size: 4, SizeOnDisk: 4096, CreationTime: created, LastModified: created.Add(time.Hour), NumberOfFiles: 1},
+		{Path: "/home/user/project1/node_modules", Size: 12, SizeOnDisk: 12288, CreationTime: created, LastModified: created, NumberOfFiles: 1, NumberOfSubdirs: 2},
+		{Path: "/home/user/project1/node_modules/package/node_modules", Size: 1 << 40, NumberOfFiles: 100000},
+	})
+}
+
+// assertSameDirectories asserts that want and got describe the same
+// directories, ignoring time zones.
+func assertSameDirectories(t *testing.T, want, got []DirectoryInfo) {
+	t.Helper()
+	assert.Len(t, got, len(want))
+	for i := range want {
+		if i >= len(got) {
+			return
+		}
+		assert.Equal(t, want[i].Path, got[i].Path)
+		assert.Equal(t, want[i].Size, got[i].Size)
+		assert.Equal(t, want[i].SizeOnDisk, got[i].SizeOnDisk)
+		assert.True(t, want[i].CreationTime.Equal(got[i].CreationTime), "creation time of %s", want[i].Path)
+		assert.True(t, want[i].LastModified.Equal(got[i].LastModified), "last modified of %s", want[i].Path)
+		assert.Equal(t, want[i].NumberOfFiles, got[i].NumberOfFiles)
+		assert.Equal(t, want[i].NumberOfSubdirs, got[i].NumberOfSubdirs)
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	snapshot := testSnapshot()
+	assert.Equal(t, "/home/user/project1/node_modules", snapshot.Directories[0].Path)
+
+	var buf bytes.Buffer
+	_, err := snapshot.WriteTo(&buf)
+	assert.NoError(t, err)
+
+	decoded, err := ReadSnapshot(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, snapshot.Host, decoded.Host)
+	assert.Equal(t, snapshot.Root, decoded.Root)
+	assert.True(t, snapshot.CreatedAt.Equal(decoded.CreatedAt))
+	assertSameDirectories(t, snapshot.Directories, decoded.Directories)
+
+	// Any damage is detected by the checksum
+	data := buf.Bytes()
+	data[len(data)/2] ^= 0xff
+	_, err = ReadSnapshot(bytes.NewReader(data))
+	assert.ErrorIs(t, err, ErrCorruptSnapshot)
+
+	_, err = ReadSnapshot(bytes.NewReader(data[:3]))
+	assert.ErrorIs(t, err, ErrCorruptSnapshot)
+}
+
+func TestReadSnapshotVersion1(t *testing.T) {
+	// Version 1 snapshots have no SizeOnDisk
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	buf := []byte(snapshotMagic)
+	buf = append(buf, 1)
+	buf = appendString(buf, "laptop")
+	buf = appendString(buf, "/home/user")
+	buf = appendTime(buf, created)
+	buf = binary.AppendUvarint(buf, 1)
+	buf = binary.AppendUvarint(buf, 0)
+	buf = appendString(buf, "/home/user/node_modules")
+	buf = binary.AppendVarint(buf, 12)
+	buf = appendTime(buf, created)
+	buf = appendTime(buf, created)
+	buf = binary.AppendUvarint(buf, 3)
+	buf = binary.AppendUvarint(buf, 1)
+	buf = binary.LittleEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+
+	decoded, err := ReadSnapshot(bytes.NewReader(buf))
+	assert.NoError(t, err)
+	assert.Equal(t, "laptop", decoded.Host)
+	assertSameDirectories(t, []DirectoryInfo{
+		{Path: "/home/user/node_modules", Size: 12, CreationTime: created, LastModified: created, NumberOfFiles: 3, NumberOfSubdirs: 1},
+	}, decoded.Directories)
+
+	// Versions from the future are refused
+	buf[len(snapshotMagic)] = snapshotVersion + 1
+	_, err = ReadSnapshot(bytes.NewReader(buf))
+	assert.Error(t, err)
+}
+
+func TestSnapshotSaveLoad(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test-snapshot-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	path := filepath.Join(tmpDir, "scan.snapshot")
+	snapshot := testSnapshot()
+
+	err = snapshot.Save(path)
+	assert.NoError(t, err)
+
+	loaded, err := LoadSnapshot(path)
+	assert.NoError(t, err)
+	assertSameDirectories(t, snapshot.Directories, loaded.Directories)
+}
+
+func TestSnapshotSaveLeavesNoTemporaryFiles(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test-snapshot-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	path := filepath.Join(tmpDir, "scan.snapshot")
+	for i := 0; i < 2; i++ {
+		err = testSnapshot().Save(path)
+		assert.NoError(t, err)
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestRecoverSnapshot(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test-recover-snapshot-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	path := filepath.Join(tmpDir, "scan.snapshot")
+	older := testSnapshot()
+	err = older.Save(path)
+	assert.NoError(t, err)
+
+	// A crash before the rename leaves a complete temporary file, a crash
+	// while writing a partial one
+	newer := testSnapshot()
+	newer.CreatedAt = older.CreatedAt.Add(time.Hour)
+	var buf bytes.Buffer
+	_, err = newer.WriteTo(&buf)
+	assert.NoError(t, err)
+	err = os.WriteFile(path+".tmp-1", buf.Bytes(), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(path+".tmp-2", buf.Bytes()[:buf.Len()/2], 0644)
+	assert.NoError(t, err)
+
+	recovered, err := RecoverSnapshot(path)
+	assert.NoError(t, err)
+	assert.True(t, newer.CreatedAt.Equal(recovered.CreatedAt))
+
+	entries, err := os.ReadDir(tmpDir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	loaded, err := LoadSnapshot(path)
+	assert.NoError(t, err)
+	assert.True(t, newer.CreatedAt.Equal(loaded.CreatedAt))
+
+	// Without an intact snapshot there is nothing to recover
+	err = os.WriteFile(path, buf.Bytes()[:buf.Len()/2], 0644)
+	assert.NoError(t, err)
+	_, err = RecoverSnapshot(path)
+	assert.ErrorIs(t, err, ErrCorruptSnapshot)
+
+	_, err = RecoverSnapshot(filepath.Join(tmpDir, "missing.snapshot"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// benchmarkSnapshot returns a snapshot of n directories resembling a real
+// scan.
+func benchmarkSnapshot(n int) *Snapshot {
+	directories := make([]DirectoryInfo, n)
+	now := time.Now()
+	for i := range directories {
+		directories[i] = DirectoryInfo{
+			Path:          filepath.Join("/home/user/projects", strconv.Itoa(i/100), "node_modules", strconv.Itoa(i)),
+			Size:          int64(i) * 4096,
+			CreationTime:  now,
+			LastModified:  now,
+			NumberOfFiles: i % 50,
+		}
+	}
+	return NewSnapshot("/home/user", directories)
+}
+
+func BenchmarkReadSnapshot(b *testing.B) {
+	var buf bytes.Buffer
+	_, err := benchmarkSnapshot(100000).WriteTo(&buf)
+	assert.NoError(b, err)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := ReadSnapshot(bytes.NewReader(buf.Bytes()))
+		assert.NoError(b, err)
+	}
+}
+
+func BenchmarkReadSnapshotJSON(b *testing.B) {
+	data, err := json.Marshal(benchmarkSnapshot(100000))
+	assert.NoError(b, err)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var snapshot Snapshot
+		err := json.Unmarshal(data, &snapshot)
+		assert.NoError(b, err)
+	}
+}
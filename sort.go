@@ -0,0 +1,95 @@
+package go_walk
+
+import (
+	"cmp"
+	"sort"
+)
+
+// SortKey is what WithSort orders directories by.
+type SortKey int
+
+const (
+	// BySize orders directories by Size.
+	BySize SortKey = iota
+	// ByPath orders directories by Path, each directory followed by
+	// everything below it.
+	ByPath
+	// ByModTime orders directories by LastModified.
+	ByModTime
+)
+
+// SortOrder is the direction WithSort orders directories in.
+type SortOrder int
+
+const (
+	// Ascending puts the smallest, first or oldest directories first.
+	Ascending SortOrder = iota
+	// Descending puts the largest, last or newest directories first.
+	Descending
+)
+
+// WithSort makes ListDirStat return the directories ordered by key in order.
+// Directories that are equal by key are ordered by path, so the result is the
+// same on every run. Without it directories are returned in the order they
+// are measured, which varies between runs.
+func WithSort(key SortKey, order SortOrder) Option {
+	return func(o *options) {
+		o.sorted = true
+		o.sortKey = key
+		o.sortOrder = order
+	}
+}
+
+// WithDeterministicOrder makes ListDirStat, and ScanAllMounts for the
+// directories of each filesystem, return directories in the same order on
+// every run: by path, with each directory followed by everything below it,
+// unless WithSort orders them otherwise. StreamDirStat still delivers
+// directories as they are measured.
+func WithDeterministicOrder() Option {
+	return func(o *options) {
+		o.deterministic = true
+	}
+}
+
+// sortResults orders directories, the results of a scan according to o, as
+// WithSort and WithDeterministicOrder ask.
+func sortResults(directories []DirectoryInfo, o *options) {
+	switch {
+	case o.sorted:
+		sortDirectories(directories, o.sortKey, o.sortOrder)
+	case o.deterministic:
+		sortDirectories(directories, ByPath, Ascending)
+	}
+}
+
+// sortDirectories sorts directories by key in order, breaking ties by path.
+func sortDirectories(directories []DirectoryInfo, key SortKey, order SortOrder) {
+	sort.Slice(directories, func(i, j int) bool {
+		a, b := &directories[i], &directories[j]
+		if c := compareBy(key, a, b); c != 0 {
+			return (c < 0) != (order == Descending)
+		}
+		return pathLess(a.Path, b.Path)
+	})
+}
+
+// compareBy compares a and b by key, returning a negative number if a comes
+// first in ascending order, a positive one if b does, and zero if they are
+// equal by key.
+func compareBy(key SortKey, a, b *DirectoryInfo) int {
+	switch key {
+	case BySize:
+		return cmp.Compare(a.Size, b.Size)
+	case ByModTime:
+		return a.LastModified.Compare(b.LastModified)
+	default:
+		switch {
+		case a.Path == b.Path:
+			return 0
+		case pathLess(a.Path, b.Path):
+			return -1
+		default:
+			return 1
+		}
+	}
+}
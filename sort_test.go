@@ -0,0 +1,102 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListDirStatWithSort(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-list-dir-stat-with-sort-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	now := time.Now()
+	projects := []struct {
+		name string
+		size int
+		age  time.Duration
+	}{
+		{"b", 300, time.Hour},
+		{"a", 100, 3 * time.Hour},
+		{"c", 200, 2 * time.Hour},
+		{"d", 200, 4 * time.Hour},
+	}
+	for _, project := range projects {
+		nodeModules := filepath.Join(tmpDir, project.name, "node_modules")
+		err = os.MkdirAll(nodeModules, 0755)
+		assert.NoError(t, err)
+		file := filepath.Join(nodeModules, "index.js")
+		err = os.WriteFile(file, make([]byte, project.size), 0644)
+		assert.NoError(t, err)
+		modified := now.Add(-project.age)
+		for _, path := range []string{file, nodeModules} {
+			err = os.Chtimes(path, modified, modified)
+			assert.NoError(t, err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		key   SortKey
+		order SortOrder
+		want  []string
+	}{
+		{"size ascending", BySize, Ascending, []string{"a", "c", "d", "b"}},
+		{"size descending", BySize, Descending, []string{"b", "c", "d", "a"}},
+		{"path ascending", ByPath, Ascending, []string{"a", "b", "c", "d"}},
+		{"path descending", ByPath, Descending, []string{"d", "c", "b", "a"}},
+		{"modification time ascending", ByModTime, Ascending, []string{"d", "a", "c", "b"}},
+		{"modification time descending", ByModTime, Descending, []string{"b", "c", "a", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			directories, err := ListDirStat(tmpDir, WithKeywords("node_modules"), WithSort(tt.key, tt.order))
+			assert.NoError(t, err)
+
+			var got []string
+			for _, dir := range directories {
+				got = append(got, filepath.Base(filepath.Dir(dir.Path)))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestListDirStatWithDeterministicOrder(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-list-dir-stat-with-deterministic-order-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	var want []string
+	for _, dir := range []string{"a", "a/node_modules", "a-b", "b", "b/node_modules/node_modules"} {
+		err = os.MkdirAll(filepath.Join(tmpDir, dir), 0755)
+		assert.NoError(t, err)
+	}
+	for _, dir := range []string{"", "a", "a/node_modules", "a-b", "b", "b/node_modules", "b/node_modules/node_modules"} {
+		want = append(want, filepath.Join(tmpDir, dir))
+	}
+
+	for i := 0; i < 5; i++ {
+		directories, err := ListDirStat(tmpDir, WithDeterministicOrder())
+		assert.NoError(t, err)
+
+		var got []string
+		for _, dir := range directories {
+			got = append(got, dir.Path)
+		}
+		assert.Equal(t, want, got)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !unix
+
+package go_walk
+
+import "io/fs"
+
+// inodeStats reports whether statOf provides file attributes on this platform.
+const inodeStats = false
+
+// statOf extracts the platform specific attributes of info. The second return
+// value reports whether they are available.
+func statOf(fs.FileInfo) (sysStat, bool) {
+	return sysStat{}, false
+}
+
+// modePermits reports whether the permission bits of info allow the process
+// to read it, and to traverse it if it is a directory.
+func modePermits(fs.FileInfo) bool {
+	return false
+}
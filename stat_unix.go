@@ -0,0 +1,78 @@
+//go:build unix
+
+package go_walk
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// inodeStats reports whether statOf provides file attributes on this platform.
+const inodeStats = true
+
+// statOf extracts the platform specific attributes of info. The second return
+// value reports whether they are available.
+func statOf(info fs.FileInfo) (sysStat, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return sysStat{}, false
+	}
+
+	return sysStat{
+		dev:    uint64(st.Dev),
+		ino:    uint64(st.Ino),
+		nlink:  uint64(st.Nlink),
+		blocks: int64(st.Blocks),
+		uid:    uint32(st.Uid),
+		gid:    uint32(st.Gid),
+	}, true
+}
+
+// modePermits reports whether the permission bits of info allow the process
+// to read it, and to traverse it if it is a directory.
+func modePermits(info fs.FileInfo) bool {
+	st, ok := statOf(info)
+	if !ok {
+		return false
+	}
+
+	want := fs.FileMode(4)
+	if info.IsDir() {
+		want |= 1
+	}
+
+	uid := os.Geteuid()
+	if uid == 0 {
+		// Permission bits never deny the superuser read access.
+		return true
+	}
+
+	perm := info.Mode().Perm()
+	switch {
+	case uint32(uid) == st.uid:
+		return (perm>>6)&want == want
+	case inGroup(st.gid):
+		return (perm>>3)&want == want
+	default:
+		return perm&want == want
+	}
+}
+
+// inGroup reports whether the process is a member of the group gid.
+func inGroup(gid uint32) bool {
+	if uint32(os.Getegid()) == gid {
+		return true
+	}
+
+	groups, err := os.Getgroups()
+	if err != nil {
+		return false
+	}
+	for _, g := range groups {
+		if uint32(g) == gid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package go_walk
+
+import "syscall"
+
+// statFSSupported reports whether statFS works on this platform.
+const statFSSupported = true
+
+// statFS returns the attributes of the filesystem that path resides on.
+func statFS(path string) (fsStat, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return fsStat{}, err
+	}
+
+	return fsStat{
+		blockSize: int64(st.Bsize),
+		total:     uint64(st.Blocks) * uint64(st.Bsize),
+		free:      uint64(st.Bfree) * uint64(st.Bsize),
+		available: uint64(st.Bavail) * uint64(st.Bsize),
+	}, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package go_walk
+
+import "errors"
+
+// statFSSupported reports whether statFS works on this platform.
+const statFSSupported = false
+
+// statFS returns the attributes of the filesystem that path resides on.
+func statFS(string) (fsStat, error) {
+	return fsStat{}, errors.ErrUnsupported
+}
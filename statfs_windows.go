@@ -0,0 +1,59 @@
+package go_walk
+
+import (
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                = syscall.NewLazyDLL("kernel32.dll")
+	procGetDiskFreeSpaceW   = kernel32.NewProc("GetDiskFreeSpaceW")
+	procGetDiskFreeSpaceExW = kernel32.NewProc("GetDiskFreeSpaceExW")
+)
+
+// statFSSupported reports whether statFS works on this platform.
+const statFSSupported = true
+
+// statFS returns the attributes of the filesystem that path resides on.
+func statFS(path string) (fsStat, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fsStat{}, err
+	}
+
+	root, err := syscall.UTF16PtrFromString(filepath.VolumeName(absPath) + `\`)
+	if err != nil {
+		return fsStat{}, err
+	}
+
+	var sectorsPerCluster, bytesPerSector, freeClusters, totalClusters uint32
+	r, _, e := procGetDiskFreeSpaceW.Call(
+		uintptr(unsafe.Pointer(root)),
+		uintptr(unsafe.Pointer(&sectorsPerCluster)),
+		uintptr(unsafe.Pointer(&bytesPerSector)),
+		uintptr(unsafe.Pointer(&freeClusters)),
+		uintptr(unsafe.Pointer(&totalClusters)),
+	)
+	if r == 0 {
+		return fsStat{}, e
+	}
+
+	var available, total, free uint64
+	r, _, e = procGetDiskFreeSpaceExW.Call(
+		uintptr(unsafe.Pointer(root)),
+		uintptr(unsafe.Pointer(&available)),
+		uintptr(unsafe.Pointer(&total)),
+		uintptr(unsafe.Pointer(&free)),
+	)
+	if r == 0 {
+		return fsStat{}, e
+	}
+
+	return fsStat{
+		blockSize: int64(sectorsPerCluster) * int64(bytesPerSector),
+		total:     total,
+		free:      free,
+		available: available,
+	}, nil
+}
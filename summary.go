@@ -0,0 +1,142 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// Summary aggregates the results of a scan.
+type Summary struct {
+	Directories int               `json:"directories" yaml:"directories"`                     // Number of directories in the results.
+	TotalSize   int64             `json:"total_size" yaml:"total_size"`                       // Combined size of the directories in bytes, without double counting nested ones.
+	Filesystems []FilesystemUsage `json:"filesystems,omitempty" yaml:"filesystems,omitempty"` // Filesystems the directories reside on.
+}
+
+// FilesystemUsage describes a filesystem encountered during a scan.
+type FilesystemUsage struct {
+	MountPoint string `json:"mount_point" yaml:"mount_point"` // Path the filesystem is mounted at.
+	Total      uint64 `json:"total" yaml:"total"`             // Total capacity of the filesystem in bytes.
+	Free       uint64 `json:"free" yaml:"free"`               // Free space on the filesystem in bytes.
+	Available  uint64 `json:"available" yaml:"available"`     // Free space available to unprivileged users in bytes.
+	Size       int64  `json:"size" yaml:"size"`               // Combined size of the directories on this filesystem in bytes.
+}
+
+// ShareOfAvailable returns Size as a fraction of the space still available
+// on the filesystem, e.g. 0.12 when the directories take up as much as 12% of
+// the remaining disk.
+func (f FilesystemUsage) ShareOfAvailable() float64 {
+	if f.Available == 0 {
+		return 0
+	}
+	return float64(f.Size) / float64(f.Available)
+}
+
+// Summarize aggregates directories, as returned by ListDirStat, into a
+// Summary that includes the capacity of every filesystem they reside on.
+// Directories nested within another directory of the results are not counted
+// twice.
+func Summarize(directories []DirectoryInfo) (Summary, error) {
+	summary := Summary{Directories: len(directories)}
+
+	usages := make(map[string]*FilesystemUsage)
+	var keys []string
+
+	for _, dir := range outermost(directories) {
+		summary.TotalSize += dir.Size
+
+		key, err := filesystemKey(dir.Path)
+		if err != nil {
+			return summary, err
+		}
+
+		usage, exists := usages[key]
+		if !exists {
+			mountPoint, err := mountPointOf(dir.Path, key)
+			if err != nil {
+				return summary, err
+			}
+
+			st, err := statFS(mountPoint)
+			if err != nil {
+				return summary, err
+			}
+
+			usage = &FilesystemUsage{
+				MountPoint: mountPoint,
+				Total:      st.total,
+				Free:       st.free,
+				Available:  st.available,
+			}
+			usages[key] = usage
+			keys = append(keys, key)
+		}
+		usage.Size += dir.Size
+	}
+
+	for _, key := range keys {
+		summary.Filesystems = append(summary.Filesystems, *usages[key])
+	}
+
+	return summary, nil
+}
+
+// outermost returns the directories that are not nested within another one of
+// directories.
+func outermost(directories []DirectoryInfo) []DirectoryInfo {
+	sorted := make([]DirectoryInfo, len(directories))
+	copy(sorted, directories)
+	sort.Slice(sorted, func(i, j int) bool {
+		return pathLess(sorted[i].Path, sorted[j].Path)
+	})
+
+	var result []DirectoryInfo
+	for _, dir := range sorted {
+		if len(result) > 0 && isWithin(dir.Path, result[len(result)-1].Path) {
+			continue
+		}
+		result = append(result, dir)
+	}
+	return result
+}
+
+// filesystemKey returns a key identifying the filesystem path resides on.
+func filesystemKey(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	if st, ok := statOf(info); ok {
+		return strconv.FormatUint(st.dev, 10), nil
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.VolumeName(absPath), nil
+}
+
+// mountPointOf returns the topmost ancestor of path that resides on the
+// filesystem identified by key.
+func mountPointOf(path, key string) (string, error) {
+	mountPoint, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		parent := filepath.Dir(mountPoint)
+		if parent == mountPoint {
+			return mountPoint, nil
+		}
+
+		parentKey, err := filesystemKey(parent)
+		if err != nil || parentKey != key {
+			return mountPoint, nil
+		}
+		mountPoint = parent
+	}
+}
@@ -0,0 +1,53 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-summarize-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	nodeModules1 := filepath.Join(tmpDir, "project1", "node_modules")
+	nestedNodeModules := filepath.Join(nodeModules1, "package", "node_modules")
+	nodeModules2 := filepath.Join(tmpDir, "project2", "node_modules")
+
+	err = os.MkdirAll(nestedNodeModules, 0755)
+	assert.NoError(t, err)
+	err = os.MkdirAll(nodeModules2, 0755)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(nestedNodeModules, "test.txt"), []byte("test content"), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(nodeModules2, "test.txt"), []byte("test"), 0644)
+	assert.NoError(t, err)
+
+	directories, err := ListDirStat(tmpDir, WithKeywords("node_modules"))
+	assert.NoError(t, err)
+
+	summary, err := Summarize(directories)
+	assert.NoError(t, err)
+
+	// The nested node_modules is already part of its parent
+	assert.Equal(t, 3, summary.Directories)
+	assert.Equal(t, int64(16), summary.TotalSize)
+
+	assert.Len(t, summary.Filesystems, 1)
+	filesystem := summary.Filesystems[0]
+	assert.NotEmpty(t, filesystem.MountPoint)
+	assert.Equal(t, int64(16), filesystem.Size)
+	assert.Greater(t, filesystem.Total, uint64(0))
+	assert.GreaterOrEqual(t, filesystem.Free, filesystem.Available)
+	if filesystem.Available > 0 {
+		assert.Greater(t, filesystem.ShareOfAvailable(), float64(0))
+	}
+}
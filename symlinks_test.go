@@ -0,0 +1,127 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/akshaybabloo/go-walk/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links requires privileges on Windows")
+	}
+
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-with-symlinks-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	project := filepath.Join(tmpDir, "project")
+	shared := filepath.Join(tmpDir, "shared")
+	for _, dir := range []string{project, shared} {
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(t, err)
+	}
+
+	err = os.WriteFile(filepath.Join(project, "main.go"), make([]byte, 10), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(shared, "lib.go"), make([]byte, 100), 0644)
+	assert.NoError(t, err)
+
+	for target, link := range map[string]string{
+		shared:                           filepath.Join(project, "shared"),
+		project:                          filepath.Join(project, "loop"),
+		filepath.Join(shared, "lib.go"):  filepath.Join(project, "lib.go"),
+		filepath.Join(tmpDir, "missing"): filepath.Join(project, "dangling"),
+	} {
+		err = os.Symlink(target, link)
+		assert.NoError(t, err)
+	}
+
+	tests := []struct {
+		name     string
+		policy   SymlinkPolicy
+		size     int64
+		files    int
+		symlinks int
+	}{
+		{"skip", SkipSymlinks, 10, 1, 0},
+		{"follow", FollowSymlinks, 210, 3, 0},
+		{"count", CountSymlinks, 10, 1, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := DirStat(project, WithSymlinks(tt.policy))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.size, dir.Size)
+			assert.Equal(t, tt.files, dir.NumberOfFiles)
+			assert.Equal(t, tt.symlinks, dir.NumberOfSymlinks)
+		})
+	}
+}
+
+func TestWithSymlinksReportsCycles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links requires privileges on Windows")
+	}
+
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"project/src/main.go": fixtures.File(10),
+		"project/src/loop":    fixtures.Symlink(".."),
+		"project/self":        fixtures.Symlink("."),
+		"project/shared":      fixtures.Symlink("../shared"),
+		"shared/lib.go":       fixtures.File(100),
+	})
+	project := filepath.Join(tmpDir, "project")
+
+	dir, err := DirStat(project, WithSymlinks(FollowSymlinks))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(110), dir.Size)
+	assert.ElementsMatch(t, []SymlinkCycle{
+		{Link: filepath.Join(project, "self"), Target: project},
+		{Link: filepath.Join(project, "src", "loop"), Target: project},
+	}, dir.SymlinkCycles)
+
+	dir, err = DirStat(project)
+	assert.NoError(t, err)
+	assert.Empty(t, dir.SymlinkCycles)
+}
+
+func TestWithFollowSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links requires privileges on Windows")
+	}
+
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"home/notes.txt":  fixtures.File(10),
+		"home/Documents":  fixtures.Symlink("../drive/docs"),
+		"home/cache":      fixtures.Symlink("../drive/cache"),
+		"home/loop":       fixtures.Symlink("."),
+		"drive/docs/a.md": fixtures.File(100),
+		"drive/cache/bin": fixtures.File(1000),
+	})
+	home := filepath.Join(tmpDir, "home")
+
+	dir, err := DirStat(home, WithFollowSymlinks("Documents"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(110), dir.Size)
+	assert.Equal(t, 2, dir.NumberOfFiles)
+
+	dir, err = DirStat(home, WithSymlinks(CountSymlinks), WithFollowSymlinks(filepath.Join(home, "Documents")))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(110), dir.Size)
+	assert.Equal(t, 2, dir.NumberOfSymlinks)
+
+	dir, err = DirStat(home, WithFollowSymlinks("loop"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), dir.Size)
+	assert.Equal(t, []SymlinkCycle{{Link: filepath.Join(home, "loop"), Target: home}}, dir.SymlinkCycles)
+}
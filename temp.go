@@ -0,0 +1,127 @@
+package go_walk
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// TempReport describes the contents of a temporary or cache location.
+type TempReport struct {
+	Location      string       `json:"location" yaml:"location"`                         // Directory that was swept.
+	Size          int64        `json:"size" yaml:"size"`                                 // Combined size of its files in bytes.
+	NumberOfFiles int          `json:"number_of_files" yaml:"number_of_files"`           // Number of files in it.
+	Stale         int64        `json:"stale" yaml:"stale"`                               // Bytes in files older than the sweep threshold.
+	StaleFiles    int          `json:"stale_files" yaml:"stale_files"`                   // Number of files older than the sweep threshold.
+	ByAge         AgeBreakdown `json:"by_age" yaml:"by_age"`                             // Size split by age.
+	CoreDumps     []string     `json:"core_dumps,omitempty" yaml:"core_dumps,omitempty"` // Crash dumps found in it.
+}
+
+// TempLocations returns the well-known temporary, cache and crash dump
+// directories of the current platform that exist.
+func TempLocations() []string {
+	home, _ := os.UserHomeDir()
+	candidates := []string{os.TempDir()}
+
+	switch runtime.GOOS {
+	case "windows":
+		localAppData := os.Getenv("LOCALAPPDATA")
+		candidates = append(candidates,
+			filepath.Join(localAppData, "Temp"),
+			filepath.Join(localAppData, "CrashDumps"),
+			filepath.Join(os.Getenv("WINDIR"), "Temp"),
+		)
+	case "darwin":
+		candidates = append(candidates, "/tmp", "/var/tmp",
+			filepath.Join(home, "Library", "Caches"),
+			filepath.Join(home, "Library", "Logs", "DiagnosticReports"),
+			"/Library/Logs/DiagnosticReports",
+			"/cores",
+		)
+	default:
+		candidates = append(candidates, "/tmp", "/var/tmp",
+			filepath.Join(home, ".cache"),
+			"/var/crash",
+			"/var/lib/systemd/coredump",
+		)
+	}
+
+	var locations []string
+	seen := make(map[string]struct{})
+	for _, candidate := range candidates {
+		if candidate == "" || !filepath.IsAbs(candidate) {
+			continue
+		}
+		candidate = filepath.Clean(candidate)
+		if _, exists := seen[candidate]; exists {
+			continue
+		}
+		if info, err := os.Stat(candidate); err != nil || !info.IsDir() {
+			continue
+		}
+		seen[candidate] = struct{}{}
+		locations = append(locations, candidate)
+	}
+	return locations
+}
+
+// SweepTemp reports the size and age of the files in each of locations, or in
+// TempLocations if none are given, counting files last modified at least
+// olderThan ago as stale, as WithOlderThan does for directories. Crash dumps
+// are listed separately. Symbolic links are skipped. Returns aggregated
+// errors alongside the reports if they occur, which is common for shared
+// locations such as /tmp.
+func SweepTemp(olderThan time.Duration, locations ...string) ([]TempReport, error) {
+	if len(locations) == 0 {
+		locations = TempLocations()
+	}
+
+	o := newOptions(WithOlderThan(olderThan))
+	now := time.Now()
+	var reports []TempReport
+	var errs ErrorList
+
+	for _, location := range locations {
+		report := TempReport{Location: location}
+
+		w, err := newScanWalker(location, o, nil)
+		if err != nil {
+			errs.add(err)
+			reports = append(reports, report)
+			continue
+		}
+		w.onFile = func(path string, entry fs.DirEntry) {
+			if entry.Type()&fs.ModeSymlink != 0 {
+				return
+			}
+			info, err := entry.Info()
+			if err != nil {
+				errs.add(err)
+				return
+			}
+
+			report.Size += info.Size()
+			report.NumberOfFiles++
+			report.ByAge.add(info.Size(), now.Sub(info.ModTime()))
+			if o.old(info.ModTime()) {
+				report.Stale += info.Size()
+				report.StaleFiles++
+			}
+			if crashDumps.matches(entry.Name()) {
+				report.CoreDumps = append(report.CoreDumps, path)
+			}
+		}
+
+		err = w.walk(context.Background(), errs.add, func(string, fs.DirEntry) bool { return true })
+		if err != nil {
+			errs.add(err)
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, errs.err()
+}
@@ -0,0 +1,64 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSweepTemp(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-sweep-temp-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	err = os.MkdirAll(filepath.Join(tmpDir, "build-1234"), 0755)
+	assert.NoError(t, err)
+
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	files := map[string]time.Time{
+		filepath.Join(tmpDir, "fresh.tmp"):              time.Now(),
+		filepath.Join(tmpDir, "build-1234", "output.o"): old,
+		filepath.Join(tmpDir, "core.4242"):              old,
+		filepath.Join(tmpDir, "last-week.log"):          time.Now().Add(-3 * 24 * time.Hour),
+	}
+	for path, modTime := range files {
+		err = os.WriteFile(path, []byte("test"), 0644)
+		assert.NoError(t, err)
+		err = os.Chtimes(path, modTime, modTime)
+		assert.NoError(t, err)
+	}
+
+	reports, err := SweepTemp(30*24*time.Hour, tmpDir)
+	assert.NoError(t, err)
+	assert.Len(t, reports, 1)
+
+	report := reports[0]
+	assert.Equal(t, tmpDir, report.Location)
+	assert.Equal(t, int64(16), report.Size)
+	assert.Equal(t, 4, report.NumberOfFiles)
+	assert.Equal(t, int64(8), report.Stale)
+	assert.Equal(t, 2, report.StaleFiles)
+	assert.Equal(t, AgeBreakdown{LastDay: 4, LastWeek: 4, Older: 8}, report.ByAge)
+	assert.Equal(t, []string{filepath.Join(tmpDir, "core.4242")}, report.CoreDumps)
+
+	assert.Contains(t, TempLocations(), filepath.Clean(os.TempDir()))
+}
+
+func TestCrashDumps(t *testing.T) {
+	assert.True(t, crashDumps.matches("core"))
+	assert.True(t, crashDumps.matches("core.1234"))
+	assert.True(t, crashDumps.matches("core.bash.1000.8f3a.1234.1700000000000000.zst"))
+	assert.True(t, crashDumps.matches("app.exe.1234.dmp"))
+	assert.False(t, crashDumps.matches("core.js"))
+	assert.False(t, crashDumps.matches("score"))
+
+	// Directories named core are not claimed by a directory preset
+	assert.Empty(t, presetsMatching("core"))
+}
@@ -0,0 +1,172 @@
+// Package testfs provides a filesystem that injects latency and failures into
+// another one, so that code reading directory trees, such as
+// go_walk.ListDirStatFS, can be tested against slow or unreliable storage
+// deterministically.
+package testfs
+
+import (
+	"io/fs"
+	"path"
+	"sync"
+	"time"
+)
+
+// FS wraps an fs.FS, such as an fstest.MapFS, and fails or delays operations
+// on chosen paths. Paths are given in the form io/fs expects, such as
+// "project/node_modules". An FS is safe for concurrent use, and faults can be
+// changed while it is in use.
+type FS struct {
+	fsys fs.FS
+
+	mu       sync.RWMutex
+	latency  time.Duration
+	faults   map[string]error
+	denied   map[string]struct{}
+	vanished map[string]struct{}
+	reads    map[string]int
+}
+
+// New returns an FS that behaves like fsys until faults are added.
+func New(fsys fs.FS) *FS {
+	return &FS{
+		fsys:     fsys,
+		faults:   make(map[string]error),
+		denied:   make(map[string]struct{}),
+		vanished: make(map[string]struct{}),
+		reads:    make(map[string]int),
+	}
+}
+
+// SetLatency delays every operation by d.
+func (f *FS) SetLatency(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency = d
+}
+
+// Fail makes every operation on name fail with err.
+func (f *FS) Fail(name string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults[path.Clean(name)] = err
+}
+
+// Deny makes opening or listing name fail with fs.ErrPermission, as if the
+// process were not allowed to read it. Its metadata can still be read, as it
+// can for a real file.
+func (f *FS) Deny(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.denied[path.Clean(name)] = struct{}{}
+}
+
+// Vanish makes name disappear after its parent has been listed: the listing
+// still contains it, but operations on it fail with fs.ErrNotExist, as if it
+// had been deleted in between.
+func (f *FS) Vanish(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.vanished[path.Clean(name)] = struct{}{}
+}
+
+// Reads returns how many times each directory has been listed with ReadDir,
+// by path, since the FS was created or last reset.
+func (f *FS) Reads() map[string]int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	reads := make(map[string]int, len(f.reads))
+	for name, n := range f.reads {
+		reads[name] = n
+	}
+	return reads
+}
+
+// Reset removes all faults and the latency and clears the read counts.
+func (f *FS) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency = 0
+	f.faults = make(map[string]error)
+	f.denied = make(map[string]struct{})
+	f.vanished = make(map[string]struct{})
+	f.reads = make(map[string]int)
+}
+
+// Open opens the file name.
+func (f *FS) Open(name string) (fs.File, error) {
+	if err := f.check("open", name); err != nil {
+		return nil, err
+	}
+	return f.fsys.Open(name)
+}
+
+// Stat returns the metadata of the file name.
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	if err := f.check("stat", name); err != nil {
+		return nil, err
+	}
+	return fs.Stat(f.fsys, name)
+}
+
+// ReadDir returns the entries of the directory name sorted by name. Entries
+// that vanished are listed, but fail when their metadata is requested.
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	f.mu.Lock()
+	f.reads[path.Clean(name)]++
+	f.mu.Unlock()
+
+	if err := f.check("readdir", name); err != nil {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(f.fsys, name)
+	for i, entry := range entries {
+		entries[i] = &dirEntry{DirEntry: entry, fs: f, path: path.Join(name, entry.Name())}
+	}
+	return entries, err
+}
+
+// check waits for the latency and returns the error the operation op on name
+// fails with, if any.
+func (f *FS) check(op, name string) error {
+	f.mu.RLock()
+	latency := f.latency
+	err := f.fault(op, name)
+	f.mu.RUnlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return nil
+}
+
+// fault returns the error the operation op on name fails with, if any. The
+// caller must hold f.mu.
+func (f *FS) fault(op, name string) error {
+	name = path.Clean(name)
+	if _, gone := f.vanished[name]; gone {
+		return fs.ErrNotExist
+	}
+	if _, denied := f.denied[name]; denied && (op == "open" || op == "readdir") {
+		return fs.ErrPermission
+	}
+	return f.faults[name]
+}
+
+// dirEntry is an entry listed by FS.ReadDir.
+type dirEntry struct {
+	fs.DirEntry
+	fs   *FS
+	path string
+}
+
+// Info returns the metadata of the entry, unless it vanished or fails.
+func (e *dirEntry) Info() (fs.FileInfo, error) {
+	if err := e.fs.check("lstat", e.path); err != nil {
+		return nil, err
+	}
+	return e.DirEntry.Info()
+}
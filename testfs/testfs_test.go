@@ -0,0 +1,67 @@
+package testfs
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFS(t *testing.T) {
+	fsys := New(fstest.MapFS{
+		"private/secret.txt": {Data: []byte("test")},
+		"public/index.html":  {Data: []byte("test")},
+		"public/gone.txt":    {Data: []byte("test")},
+		"broken/file":        {Data: []byte("test")},
+	})
+	fsys.Deny("private")
+	fsys.Vanish("public/gone.txt")
+	fsys.Fail("broken", errors.New("input/output error"))
+
+	_, err := fs.ReadDir(fsys, "private")
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	info, err := fs.Stat(fsys, "private")
+	assert.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	entries, err := fs.ReadDir(fsys, "public")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	for _, entry := range entries {
+		_, err := entry.Info()
+		if entry.Name() == "gone.txt" {
+			assert.ErrorIs(t, err, fs.ErrNotExist)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+	_, err = fs.ReadFile(fsys, "public/gone.txt")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	_, err = fs.Stat(fsys, "broken")
+	var pathErr *fs.PathError
+	assert.ErrorAs(t, err, &pathErr)
+	assert.Equal(t, "broken", pathErr.Path)
+	assert.Equal(t, "stat", pathErr.Op)
+
+	// Everything works again once the faults are removed
+	fsys.Reset()
+	fsys.SetLatency(10 * time.Millisecond)
+	start := time.Now()
+	data, err := fs.ReadFile(fsys, "public/gone.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "test", string(data))
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+
+	fsys.SetLatency(0)
+	_, err = fs.ReadDir(fsys, "public")
+	assert.NoError(t, err)
+	_, err = fs.ReadDir(fsys, "public")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"public": 2}, fsys.Reads())
+
+	assert.NoError(t, fstest.TestFS(fsys, "private/secret.txt", "public/index.html"))
+}
@@ -0,0 +1,22 @@
+package go_walk
+
+import "context"
+
+// TopNBySize returns the n largest directories ListDirStat would return for
+// root and opts, largest first. Only those n are kept in memory during the
+// scan, however many directories match. Returns aggregated errors alongside
+// the directories if they occur.
+func TopNBySize(root string, n int, opts ...Option) ([]DirectoryInfo, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	h := &sizeHeap{}
+	err := collectDirStat(context.Background(), root, newOptions(opts...), func(dirStat DirectoryInfo) {
+		h.offer(dirStat, n)
+	})
+	if h.Len() == 0 {
+		return nil, err
+	}
+	return h.largestFirst(), err
+}
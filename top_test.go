@@ -0,0 +1,47 @@
+package go_walk
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopNBySize(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-top-n-by-size-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	for i := 1; i <= 10; i++ {
+		nodeModules := filepath.Join(tmpDir, "project"+strconv.Itoa(i), "node_modules")
+		err = os.MkdirAll(nodeModules, 0755)
+		assert.NoError(t, err)
+		err = os.WriteFile(filepath.Join(nodeModules, "index.js"), make([]byte, i*100), 0644)
+		assert.NoError(t, err)
+	}
+
+	directories, err := TopNBySize(tmpDir, 3, WithKeywords("node_modules"))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 3)
+	for i, dir := range directories {
+		assert.Equal(t, filepath.Join(tmpDir, "project"+strconv.Itoa(10-i), "node_modules"), dir.Path)
+		assert.Equal(t, int64((10-i)*100), dir.Size)
+	}
+
+	directories, err = TopNBySize(tmpDir, 20, WithKeywords("node_modules"))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 10)
+
+	directories, err = TopNBySize(tmpDir, 0, WithKeywords("node_modules"))
+	assert.NoError(t, err)
+	assert.Empty(t, directories)
+
+	_, err = TopNBySize(filepath.Join(tmpDir, "missing"), 3)
+	assert.Error(t, err)
+}
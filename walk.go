@@ -1,150 +1,588 @@
 package go_walk
 
 import (
+	"context"
 	"errors"
 	"io/fs"
 	"os"
-	"path/filepath"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// DirectoryInfo holds metadata about a directory.
+// DirectoryInfo holds metadata about a directory. It serializes to JSON and
+// YAML with lower_snake_case field names and RFC 3339 times.
 type DirectoryInfo struct {
-	Path            string    // Absolute path of the directory.
-	Size            int64     // Size of the directory in bytes.
-	CreationTime    time.Time // When the directory was created.
-	LastModified    time.Time // When the directory was last modified.
-	NumberOfFiles   int       // Number of files in the directory.
-	NumberOfSubdirs int       // Number of subdirectories within the directory.
+	Path            string    `json:"path" yaml:"path"`                           // Absolute path of the directory.
+	Size            int64     `json:"size" yaml:"size"`                           // Apparent size of the files in the directory in bytes.
+	SizeOnDisk      int64     `json:"size_on_disk" yaml:"size_on_disk"`           // Space allocated to the directory and its contents in bytes, as reported by du.
+	CreationTime    time.Time `json:"creation_time" yaml:"creation_time"`         // When the directory was created.
+	LastModified    time.Time `json:"last_modified" yaml:"last_modified"`         // When the directory, or any file or directory in it, was last modified.
+	NumberOfFiles   int       `json:"number_of_files" yaml:"number_of_files"`     // Number of files in the directory.
+	NumberOfSubdirs int       `json:"number_of_subdirs" yaml:"number_of_subdirs"` // Number of subdirectories within the directory.
+	ComputedAt      time.Time `json:"computed_at" yaml:"computed_at"`             // When the metadata was measured, which for cached results can be well in the past.
+
+	// Elapsed is how long measuring the directory took, including everything
+	// below it, measured only with WithTiming. Slow subtrees, such as network
+	// mounts or huge directories, stand out by it. It is not stored in
+	// snapshots.
+	Elapsed time.Duration `json:"elapsed,omitempty" yaml:"elapsed,omitempty"`
+
+	// NewestFileModTime and OldestFileModTime are the modification times of
+	// the most and least recently modified files within the directory, zero
+	// if it has none. Unlike LastModified they ignore directories, whose
+	// times change whenever entries are added or removed, so they tell when
+	// the contents were last worked on. They are not stored in snapshots.
+	NewestFileModTime time.Time `json:"newest_file_mod_time" yaml:"newest_file_mod_time"`
+	OldestFileModTime time.Time `json:"oldest_file_mod_time" yaml:"oldest_file_mod_time"`
+
+	// LargestFile and AverageFileSize, measured only with WithFileSizeStats,
+	// tell whether a directory is big because of a few large files or a great
+	// many small ones. LargestFile is nil for directories without files. They
+	// are not stored in snapshots.
+	LargestFile     *FileInfo `json:"largest_file,omitempty" yaml:"largest_file,omitempty"`
+	AverageFileSize int64     `json:"average_file_size,omitempty" yaml:"average_file_size,omitempty"`
+
+	// ByExtension breaks the files of the directory down by lower case
+	// extension, including the dot or empty for files without one, measured
+	// only with WithExtensionStats. It is not stored in snapshots.
+	ByExtension map[string]ExtStat `json:"by_extension,omitempty" yaml:"by_extension,omitempty"`
+
+	// NumberOfSymlinks is the number of symbolic links within the directory,
+	// counted only with WithSymlinks(CountSymlinks).
+	NumberOfSymlinks int `json:"number_of_symlinks,omitempty" yaml:"number_of_symlinks,omitempty"`
+
+	// SymlinkCycles lists the symbolic links within the directory that lead
+	// back to a directory above them, found only when following links with
+	// WithSymlinks(FollowSymlinks) or WithFollowSymlinks. They are not
+	// followed, so that they cannot hang the scan. They are not stored in
+	// snapshots.
+	SymlinkCycles []SymlinkCycle `json:"symlink_cycles,omitempty" yaml:"symlink_cycles,omitempty"`
+
+	// Partial marks an intermediate result of a directory still being
+	// measured, delivered by StreamDirStat with WithProgress. The final result
+	// for the same path follows later.
+	Partial bool `json:"partial,omitempty" yaml:"partial,omitempty"`
+
+	// Incomplete marks a directory containing entries that could not be
+	// read, such as subdirectories it is not permitted to list. Its metadata
+	// covers the rest, and SkippedEntries counts the entries left out, where
+	// an unreadable directory counts once.
+	Incomplete     bool `json:"incomplete,omitempty" yaml:"incomplete,omitempty"`
+	SkippedEntries int  `json:"skipped_entries,omitempty" yaml:"skipped_entries,omitempty"`
+
+	// Annotations holds what the Annotators of the scan added, such as tags,
+	// risk scores or ownership. It is not stored in snapshots.
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// SymlinkCycle is a symbolic link that leads back to a directory above it.
+type SymlinkCycle struct {
+	Link   string `json:"link" yaml:"link"`     // Path of the symbolic link.
+	Target string `json:"target" yaml:"target"` // Path of the directory above it that it leads to.
+}
+
+// lastChanged returns when the contents of the directory were last changed:
+// when its newest file was modified, or LastModified if it has no files or
+// they were not measured, as for directories read from snapshots.
+func (d DirectoryInfo) lastChanged() time.Time {
+	if !d.NewestFileModTime.IsZero() {
+		return d.NewestFileModTime
+	}
+	return d.LastModified
+}
+
+// Annotate sets the annotation key to value.
+func (d *DirectoryInfo) Annotate(key, value string) {
+	if d.Annotations == nil {
+		d.Annotations = make(map[string]string)
+	}
+	d.Annotations[key] = value
 }
 
-// ListDirStat lists directories matching the provided keywords in dirPath
-// and returns their metadata. If no keywords are provided, all directories
+// ListDirStat lists the directories in dirPath selected by opts, such as
+// WithKeywords, and returns their metadata. Without options all directories
 // are matched. Returns aggregated errors if they occur.
-func ListDirStat(dirPath string, keywords ...string) ([]DirectoryInfo, error) {
-	pathStat, err := os.Stat(dirPath)
+func ListDirStat(dirPath string, opts ...Option) ([]DirectoryInfo, error) {
+	return ListDirStatContext(context.Background(), dirPath, opts...)
+}
+
+// ListDirStatContext is like ListDirStat but stops the scan promptly once ctx
+// is done, returning the directories computed so far together with
+// ctx.Err().
+func ListDirStatContext(ctx context.Context, dirPath string, opts ...Option) ([]DirectoryInfo, error) {
+	return listDirStat(ctx, dirPath, newOptions(opts...))
+}
+
+// ListDirStatMatching lists directories in dirPath for which m reports a
+// match and returns their metadata. A nil Matcher matches all directories.
+// Returns aggregated errors if they occur.
+func ListDirStatMatching(dirPath string, m Matcher, opts ...Option) ([]DirectoryInfo, error) {
+	o := newOptions(opts...)
+	o.matcher = m
+	return listDirStat(context.Background(), dirPath, o)
+}
+
+// StreamDirStat is like ListDirStat but delivers the metadata of every
+// matching directory on the first channel as soon as it is computed, instead
+// of accumulating the results. Errors are delivered on the second channel as
+// they occur. Both channels are closed once the scan has finished, and both
+// must be drained until then.
+func StreamDirStat(dirPath string, opts ...Option) (<-chan DirectoryInfo, <-chan error) {
+	dirChan, errChan, err := streamDirStat(context.Background(), dirPath, newOptions(opts...))
 	if err != nil {
-		return nil, err
+		dirs := make(chan DirectoryInfo)
+		errs := make(chan error, 1)
+		errs <- err
+		close(dirs)
+		close(errs)
+		return dirs, errs
+	}
+	return dirChan, errChan
+}
+
+// listDirStat lists directories in dirPath according to o until ctx is done
+// or the results reach the budget of WithMaxMemory.
+func listDirStat(ctx context.Context, dirPath string, o *options) ([]DirectoryInfo, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var directories []DirectoryInfo
+	var held int64
+	exceeded := false
+	err := collectDirStat(ctx, dirPath, o, func(dirStat DirectoryInfo) {
+		if exceeded {
+			return
+		}
+		if o.maxMemory > 0 {
+			held += dirStat.memSize()
+			if held > o.maxMemory {
+				exceeded = true
+				cancel()
+				return
+			}
+		}
+		directories = append(directories, dirStat)
+	})
+	if exceeded {
+		err = ErrMemoryBudget
+	}
+	sortResults(directories, o)
+	return directories, err
+}
+
+// collectDirStat scans dirPath according to o until ctx is done, handing the
+// final metadata of every matching directory to fn, and returns the errors
+// that occurred, or the error of ctx if it is done.
+func collectDirStat(ctx context.Context, dirPath string, o *options, fn func(DirectoryInfo)) error {
+	dirChan, errChan, err := streamDirStat(ctx, dirPath, o)
+	if err != nil {
+		return err
+	}
+
+	var errs ErrorList
+
+	for dirChan != nil || errChan != nil {
+		select {
+		case dirStat, ok := <-dirChan:
+			if !ok {
+				dirChan = nil
+				continue
+			}
+			if !dirStat.Partial {
+				fn(dirStat)
+			}
+		case e, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			errs.add(e)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return errs.err()
+}
+
+// streamDirStat starts a scan of dirPath according to o, which stops once ctx
+// is done, and returns the channels the results and errors are delivered on.
+// Both are closed once the scan has finished.
+func streamDirStat(ctx context.Context, dirPath string, o *options) (chan DirectoryInfo, chan error, error) {
+	pathStat, err := o.stat(dirPath)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	if !pathStat.IsDir() {
-		return nil, errors.New("the path provided is not a directory")
+		return nil, nil, errors.New("the path provided is not a directory")
+	}
+
+	m, err := o.matches()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w, err := newScanWalker(dirPath, o, m)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	dirChan := make(chan DirectoryInfo)
 	errChan := make(chan error)
-	var directories []DirectoryInfo
-	var mu sync.Mutex
-	var errStrings []string
 
-	keywordSet := make(map[string]struct{})
-	for _, keyword := range keywords {
-		keywordSet[keyword] = struct{}{}
-	}
+	ctx, cancel := context.WithCancel(ctx)
+	report := newReporter(o, func(err error) { errChan <- err }, cancel)
 
+	queue := newWorkQueue(o.scheduling)
 	wg := &sync.WaitGroup{}
 
-	directoryVisitor := func(path string, entry fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if entry.IsDir() {
-			_, exists := keywordSet[entry.Name()]
-			if len(keywordSet) == 0 || exists {
-				wg.Add(1)
-
-				go func(p string) {
-					defer wg.Done()
-					dirStat, err := calculateDirStats(p)
-					if err != nil {
-						errChan <- err
+	for i := 0; i < o.workerCount(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p, ok := queue.pop(); ok; p, ok = queue.pop() {
+				if ctx.Err() != nil {
+					// Drain the queue without doing any more work.
+					continue
+				}
+				// Matching directories nested within p are measured along the
+				// way, so that no subtree is read twice.
+				emit := func(dirStat DirectoryInfo) {
+					if !o.kept(dirStat) {
 						return
 					}
+					for _, annotator := range o.annotators {
+						annotator.Annotate(&dirStat)
+					}
 					dirChan <- dirStat
-				}(path)
+				}
+
+				r, err := newRollup(ctx, dirPath, p, o, m, emit)
+				if err != nil {
+					report(err)
+					continue
+				}
+				if p == dirPath {
+					// The whole tree matched, so there is no other work for
+					// the remaining workers.
+					r.concurrently(o.workerCount())
+				}
+				if o.progress > 0 {
+					r.onProgress(o.progress, func(partial DirectoryInfo) {
+						partial.Partial = true
+						dirChan <- partial
+					})
+				}
+				dirStat, measured, err := r.run(p)
+				if err != nil {
+					report(err)
+				}
+				if !measured || ctx.Err() != nil {
+					// The directory vanished, could not be stat'ed or the
+					// scan stopped, and there is nothing to deliver.
+					continue
+				}
+				emit(dirStat)
 			}
-		}
-		return nil
+		}()
 	}
 
 	go func() {
-		err := filepath.WalkDir(dirPath, directoryVisitor)
+		err := w.walk(ctx, report, func(path string, entry fs.DirEntry) bool {
+			queue.push(topLevelGroup(dirPath, path), path)
+			// The worker measuring path reports what matches below it.
+			return false
+		})
 		if err != nil {
-			errChan <- err
+			report(err)
 		}
+		queue.close()
 		wg.Wait()
+		cancel()
 		close(dirChan)
 		close(errChan)
 	}()
 
-	for dirStat := range dirChan {
-		mu.Lock()
-		directories = append(directories, dirStat)
-		mu.Unlock()
-	}
+	return dirChan, errChan, nil
+}
 
-	for e := range errChan {
-		mu.Lock()
-		errStrings = append(errStrings, e.Error())
-		mu.Unlock()
+// newReporter returns a function passing the errors of a scan to deliver
+// according to o. With FailFast the first error reported stops the scan by
+// calling cancel, and no others follow it.
+func newReporter(o *options, deliver func(error), cancel context.CancelFunc) func(error) {
+	var failed atomic.Bool
+	return func(err error) {
+		switch o.errorPolicy {
+		case Ignore:
+			return
+		case FailFast:
+			if !failed.CompareAndSwap(false, true) {
+				return
+			}
+			cancel()
+		}
+		deliver(err)
 	}
+}
 
-	if len(errStrings) > 0 {
-		return directories, errors.New("errors occurred during directory processing: " + strings.Join(errStrings, "; "))
-	}
+// scanWalker finds the directories of a scan to measure, walking the tree
+// below root according to o: excluded directories, directories on other
+// filesystems with WithOneFileSystem and directories below WithMaxDepth are
+// skipped.
+type scanWalker struct {
+	root     string
+	o        *options
+	m        Matcher
+	rootDev  uint64
+	checkDev bool
 
-	return directories, nil
+	// onFile, if set, is called for every entry other than a directory in
+	// the directories walked.
+	onFile func(path string, entry fs.DirEntry)
 }
 
-// calculateDirStats computes and returns the statistics for a directory.
-func calculateDirStats(path string) (DirectoryInfo, error) {
-	var totalSize int64
-	var numberOfFiles int
-	var numberOfSubdirs int
-	var creationTime time.Time
-	var lastModified time.Time
-
-	err := filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+// newScanWalker returns a scanWalker for the directories below root matched
+// by m, or every directory if m is nil.
+func newScanWalker(root string, o *options, m Matcher) (*scanWalker, error) {
+	w := &scanWalker{root: root, o: o, m: m}
+	if o.oneFilesystem {
+		var err error
+		w.rootDev, w.checkDev, err = o.deviceOf(root)
 		if err != nil {
-			return err
+			return nil, err
 		}
+	}
+	return w, nil
+}
+
+// walk walks the tree until ctx is done, calling match for every matching
+// directory. The directories below a match are walked only if match returns
+// true. Unreadable directories are passed to report and skipped.
+func (w *scanWalker) walk(ctx context.Context, report func(error), match func(path string, entry fs.DirEntry) bool) error {
+	o := w.o
 
-		info, err := entry.Info()
+	// Directories the walker could not list for lack of file descriptors
+	// are walked again after backing off, counted here by path.
+	var visit fs.WalkDirFunc
+	retries := make(map[string]int)
+
+	directoryVisitor := func(path string, entry fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return fs.SkipAll
+		}
 		if err != nil {
-			return err
+			if entry != nil && entry.IsDir() && tooManyOpenFiles(err) && retries[path] < fdRetries {
+				o.fds.backOff(retries[path])
+				retries[path]++
+				return o.walkDir(path, visit)
+			}
+			// Record the unreadable subtree and carry on with the rest.
+			report(err)
+			return nil
 		}
 
 		if entry.IsDir() {
-			numberOfSubdirs++
-		} else {
-			totalSize += info.Size()
-			numberOfFiles++
+			if path != w.root && o.excluded(path) {
+				return fs.SkipDir
+			}
+			if w.checkDev && path != w.root {
+				if info, err := entry.Info(); err == nil {
+					if st, ok := statOf(info); ok && st.dev != w.rootDev {
+						return fs.SkipDir
+					}
+				}
+			}
+			if w.m == nil || w.m.Match(path, entry) {
+				if !match(path, entry) {
+					return fs.SkipDir
+				}
+			}
+			if o.maxDepth > 0 && depthBelow(w.root, path) >= o.maxDepth {
+				return fs.SkipDir
+			}
+		} else if w.onFile != nil {
+			w.onFile(path, entry)
 		}
+		return nil
+	}
+	visit = chain(directoryVisitor, o.middlewares)
+
+	return o.walkDir(w.root, visit)
+}
+
+// DirStat returns the metadata of the single directory at path, measured
+// recursively as ListDirStat measures each directory it finds and honouring
+// the same options where they apply, such as WithExclude or WithAnnotators.
+// Errors are returned alongside what could be measured, subject to
+// WithErrorPolicy.
+func DirStat(path string, opts ...Option) (DirectoryInfo, error) {
+	pathStat, err := os.Stat(path)
+	if err != nil {
+		return DirectoryInfo{}, err
+	}
+
+	if !pathStat.IsDir() {
+		return DirectoryInfo{}, errors.New("the path provided is not a directory")
+	}
+
+	o := newOptions(opts...)
+	dirStat, err := calculateDirStats(context.Background(), path, o)
+	if err != nil && o.errorPolicy == FailFast {
+		return DirectoryInfo{}, err
+	}
+	for _, annotator := range o.annotators {
+		annotator.Annotate(&dirStat)
+	}
+	if o.errorPolicy == Ignore {
+		return dirStat, nil
+	}
+	return dirStat, err
+}
+
+// ShallowDirStat returns the metadata of the immediate subdirectories of
+// dirPath, each with its recursive size, similar to "du -d1". Returns
+// aggregated errors if they occur.
+func ShallowDirStat(dirPath string) ([]DirectoryInfo, error) {
+	o := newOptions()
+	return shallowDirStat(dirPath, o, make(chan struct{}, o.workerCount()))
+}
+
+// shallowDirStat returns the metadata of the immediate subdirectories of
+// dirPath according to o, measuring as many at once as sem holds. Scans
+// sharing sem measure that many between them.
+func shallowDirStat(dirPath string, o *options, sem chan struct{}) ([]DirectoryInfo, error) {
+	sem <- struct{}{}
+	entries, err := o.readDir(dirPath)
+	<-sem
+	if err != nil {
+		return nil, err
+	}
+
+	var rootDev uint64
+	var checkDev bool
+	if o.oneFilesystem {
+		var err error
+		rootDev, checkDev, err = o.deviceOf(dirPath)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-		if creationTime.IsZero() || info.ModTime().Before(creationTime) {
-			creationTime = info.ModTime()
+	var directories []DirectoryInfo
+	var mu sync.Mutex
+	var errs ErrorList
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	wg := &sync.WaitGroup{}
+	for _, entry := range entries {
+		if !entry.IsDir() || o.excluded(o.join(dirPath, entry.Name())) {
+			continue
 		}
 
-		if lastModified.IsZero() || info.ModTime().After(lastModified) {
-			lastModified = info.ModTime()
+		if checkDev {
+			if info, err := entry.Info(); err == nil {
+				if st, ok := statOf(info); ok && st.dev != rootDev {
+					continue
+				}
+			}
 		}
 
-		return nil
-	})
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(p string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			dirStat, err := calculateDirStats(ctx, p, o)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if o.errorPolicy == FailFast {
+					if len(errs) == 0 {
+						errs.add(err)
+						cancel()
+					}
+					return
+				}
+				errs.add(err)
+			}
+			directories = append(directories, dirStat)
+		}(o.join(dirPath, entry.Name()))
+	}
+	wg.Wait()
 
+	sortResults(directories, o)
+	if o.errorPolicy == Ignore {
+		return directories, nil
+	}
+	return directories, errs.err()
+}
+
+// DirSize returns the total recursive size in bytes of the files within
+// dirPath. Subdirectories are measured concurrently and symbolic links are not
+// counted, as with DirStat. Returns aggregated errors alongside the size of
+// what could be measured if they occur.
+func DirSize(dirPath string) (int64, error) {
+	pathStat, err := os.Stat(dirPath)
 	if err != nil {
-		return DirectoryInfo{}, err
+		return 0, err
+	}
+	if !pathStat.IsDir() {
+		return 0, errors.New("the path provided is not a directory")
 	}
 
-	return DirectoryInfo{
-		Path:            path,
-		Size:            totalSize,
-		CreationTime:    creationTime,
-		LastModified:    lastModified,
-		NumberOfFiles:   numberOfFiles,
-		NumberOfSubdirs: numberOfSubdirs,
-	}, nil
+	o := newOptions()
+	r, err := newRollup(context.Background(), dirPath, dirPath, o, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	r.concurrently(o.workerCount())
+
+	dirStat, _, err := r.run(dirPath)
+	return dirStat.Size, err
+}
+
+// CountFiles returns the number of files within dirPath, recursively. If
+// globs are provided, only files whose base name matches one of them are
+// counted, using the syntax of filepath.Match. Subdirectories are counted
+// concurrently and symbolic links are not counted, as with DirStat. Returns
+// aggregated errors alongside the number of files that could be counted if
+// they occur.
+func CountFiles(dirPath string, globs ...string) (int, error) {
+	pathStat, err := os.Stat(dirPath)
+	if err != nil {
+		return 0, err
+	}
+	if !pathStat.IsDir() {
+		return 0, errors.New("the path provided is not a directory")
+	}
+
+	o := newOptions()
+	if len(globs) > 0 {
+		o.files = Glob(globs...)
+	}
+
+	r, err := newRollup(context.Background(), dirPath, dirPath, o, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	r.concurrently(o.workerCount())
+
+	dirStat, _, err := r.run(dirPath)
+	return dirStat.NumberOfFiles, err
+}
+
+// calculateDirStats computes and returns the statistics for a directory
+// according to o, giving up once ctx is done.
+func calculateDirStats(ctx context.Context, path string, o *options) (DirectoryInfo, error) {
+	r, err := newRollup(ctx, path, path, o, nil, nil)
+	if err != nil {
+		return DirectoryInfo{}, err
+	}
+	dirStat, _, err := r.run(path)
+	return dirStat, err
 }
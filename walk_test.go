@@ -1,10 +1,20 @@
 package go_walk
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
+	"time"
 
+	"github.com/akshaybabloo/go-walk/fixtures"
+	"github.com/akshaybabloo/go-walk/testfs"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -34,7 +44,7 @@ func TestListDirStat(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Call ListDirStat
-	directories, err := ListDirStat(tmpDir, "node_modules")
+	directories, err := ListDirStat(tmpDir, WithKeywords("node_modules"))
 	assert.NoError(t, err)
 
 	// Check the results
@@ -107,3 +117,724 @@ func TestListDirStatNoKeyword(t *testing.T) {
 	assert.True(t, foundDirs[nodeModules2], "Directory %s was not found", nodeModules2)
 	assert.True(t, foundDirs[nestedNodeModules], "Directory %s was not found", nestedNodeModules)
 }
+
+func TestListDirStatNested(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-list-dir-stat-nested-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	outer := filepath.Join(tmpDir, "project1", "node_modules")
+	inner := filepath.Join(outer, "lodash", "node_modules")
+	innermost := filepath.Join(inner, "debug", "node_modules")
+
+	err = os.MkdirAll(innermost, 0755)
+	assert.NoError(t, err)
+	for _, dir := range []string{outer, inner, innermost} {
+		err = os.WriteFile(filepath.Join(dir, "index.js"), []byte("test"), 0644)
+		assert.NoError(t, err)
+	}
+
+	directories, err := ListDirStat(tmpDir, WithKeywords("node_modules"))
+	assert.NoError(t, err)
+
+	type stats struct {
+		size           int64
+		files, subdirs int
+	}
+	got := make(map[string]stats)
+	for _, dir := range directories {
+		got[dir.Path] = stats{dir.Size, dir.NumberOfFiles, dir.NumberOfSubdirs}
+	}
+	assert.Equal(t, map[string]stats{
+		outer:     {12, 3, 5},
+		inner:     {8, 2, 3},
+		innermost: {4, 1, 1},
+	}, got)
+}
+
+func TestListDirStatWithOneFileSystem(t *testing.T) {
+	// /dev/shm and /dev/pts are usually separate filesystems mounted on /dev
+	rootDev, ok, err := newOptions().deviceOf("/dev")
+	if err != nil || !ok {
+		t.Skip("device numbers of /dev are not available")
+	}
+
+	var mountPoints []string
+	for _, dir := range []string{"/dev/shm", "/dev/pts"} {
+		if dev, _, err := newOptions().deviceOf(dir); err == nil && dev != rootDev {
+			mountPoints = append(mountPoints, dir)
+		}
+	}
+	if len(mountPoints) == 0 {
+		t.Skip("no filesystems are mounted below /dev")
+	}
+
+	directories, _ := ListDirStat("/dev", WithOneFileSystem(), WithMaxDepth(1))
+	assert.NotEmpty(t, directories)
+	for _, dir := range directories {
+		assert.NotContains(t, mountPoints, dir.Path)
+	}
+}
+
+func TestListDirStatContext(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-list-dir-stat-context-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	for _, dir := range []string{"project1/node_modules", "project2/node_modules"} {
+		err = os.MkdirAll(filepath.Join(tmpDir, dir), 0755)
+		assert.NoError(t, err)
+	}
+
+	directories, err := ListDirStatContext(context.Background(), tmpDir, WithKeywords("node_modules"))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	directories, err = ListDirStatContext(ctx, tmpDir, WithKeywords("node_modules"))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, directories)
+}
+
+func TestListDirStatContextCancelledConcurrently(t *testing.T) {
+	tree := fstest.MapFS{}
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			tree[fmt.Sprintf("project%d/dir%d/index.js", i, j)] = &fstest.MapFile{Data: []byte("test")}
+		}
+	}
+	fsys := testfs.New(tree)
+	fsys.SetLatency(time.Millisecond)
+	withFS := func(o *options) { o.fsys = fsys }
+
+	// Without keywords the root's subdirectories are measured concurrently,
+	// and none of them may deliver anything once the scan has stopped.
+	for i := 0; i < 10; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		var delivered atomic.Int32
+		stop := AnnotatorFunc(func(*DirectoryInfo) {
+			if delivered.Add(1) == 3 {
+				cancel()
+			}
+		})
+		_, err := ListDirStatContext(ctx, ".", withFS, WithWorkers(2), WithAnnotators(stop))
+		assert.ErrorIs(t, err, context.Canceled)
+		cancel()
+	}
+
+	// The same goes for the first error stopping the scan
+	fsys.Fail("project3/dir3", fs.ErrInvalid)
+	for i := 0; i < 10; i++ {
+		_, err := ListDirStatContext(context.Background(), ".", withFS, WithWorkers(2), WithErrorPolicy(FailFast))
+		assert.ErrorIs(t, err, fs.ErrInvalid)
+	}
+}
+
+func TestListDirStatWithWorkers(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-list-dir-stat-with-workers-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	for i := 0; i < 10; i++ {
+		err = os.MkdirAll(filepath.Join(tmpDir, fmt.Sprintf("project%d", i), "node_modules"), 0755)
+		assert.NoError(t, err)
+	}
+
+	for _, workers := range []int{-1, 1, 3, 32} {
+		directories, err := ListDirStat(tmpDir, WithKeywords("node_modules"), WithWorkers(workers))
+		assert.NoError(t, err)
+		assert.Len(t, directories, 10)
+	}
+}
+
+func TestListDirStatWithMatchMode(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-list-dir-stat-with-match-mode-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	nodeModules := filepath.Join(tmpDir, "project1", "node_modules")
+	buildCache := filepath.Join(tmpDir, "project1", "build-cache")
+	scratch := filepath.Join(tmpDir, "project2", "scratch.tmp")
+
+	for _, dir := range []string{nodeModules, buildCache, scratch} {
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(t, err)
+	}
+
+	tests := []struct {
+		name     string
+		mode     MatchMode
+		keywords []string
+		want     []string
+	}{
+		{"exact", MatchExact, []string{"node_modules", "*.tmp"}, []string{nodeModules}},
+		{"glob", MatchGlob, []string{"node_modules", "*.tmp"}, []string{nodeModules, scratch}},
+		{"regex", MatchRegex, []string{"node_modules", ".*cache.*"}, []string{nodeModules, buildCache}},
+		{"regex is anchored", MatchRegex, []string{"cache"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			directories, err := ListDirStat(tmpDir, WithKeywords(tt.keywords...), WithMatchMode(tt.mode))
+			assert.NoError(t, err)
+
+			var paths []string
+			for _, dir := range directories {
+				paths = append(paths, dir.Path)
+			}
+			assert.ElementsMatch(t, tt.want, paths)
+		})
+	}
+
+	_, err = ListDirStat(tmpDir, WithKeywords("[a-"), WithMatchMode(MatchGlob))
+	assert.Error(t, err)
+
+	_, err = ListDirStat(tmpDir, WithKeywords("(unclosed"), WithMatchMode(MatchRegex))
+	assert.Error(t, err)
+}
+
+func TestListDirStatWithExclude(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-list-dir-stat-with-exclude-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	project := filepath.Join(tmpDir, "project1")
+	for _, dir := range []string{
+		filepath.Join(project, ".git", "node_modules"),
+		filepath.Join(project, "vendor"),
+		filepath.Join(project, "node_modules"),
+		filepath.Join(tmpDir, "backup", "node_modules"),
+	} {
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(t, err)
+	}
+
+	for _, file := range []string{
+		filepath.Join(project, "main.go"),
+		filepath.Join(project, "vendor", "lib.go"),
+		filepath.Join(project, ".git", "HEAD"),
+	} {
+		err = os.WriteFile(file, []byte("test"), 0644)
+		assert.NoError(t, err)
+	}
+
+	directories, err := ListDirStat(tmpDir, WithExclude(".git", "vendor", filepath.Join(tmpDir, "backup")))
+	assert.NoError(t, err)
+
+	sizes := make(map[string]int64)
+	for _, dir := range directories {
+		sizes[dir.Path] = dir.Size
+	}
+	assert.Equal(t, map[string]int64{
+		tmpDir:                                 4,
+		project:                                4,
+		filepath.Join(project, "node_modules"): 0,
+	}, sizes)
+}
+
+func TestListDirStatWithMaxDepth(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-list-dir-stat-with-max-depth-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	deep := filepath.Join(tmpDir, "a", "b", "c")
+	err = os.MkdirAll(deep, 0755)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(deep, "file.txt"), []byte("test"), 0644)
+	assert.NoError(t, err)
+
+	directories, err := ListDirStat(tmpDir, WithMaxDepth(2))
+	assert.NoError(t, err)
+
+	sizes := make(map[string]int64)
+	for _, dir := range directories {
+		sizes[dir.Path] = dir.Size
+	}
+	assert.Equal(t, map[string]int64{
+		tmpDir:                          4,
+		filepath.Join(tmpDir, "a"):      4,
+		filepath.Join(tmpDir, "a", "b"): 4,
+	}, sizes)
+}
+
+func TestStreamDirStat(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-stream-dir-stat-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	for _, dir := range []string{"project1/node_modules", "project2/node_modules", "project3/src"} {
+		err = os.MkdirAll(filepath.Join(tmpDir, dir), 0755)
+		assert.NoError(t, err)
+	}
+
+	dirChan, errChan := StreamDirStat(tmpDir, WithKeywords("node_modules"))
+
+	var paths []string
+	for dirChan != nil || errChan != nil {
+		select {
+		case dir, ok := <-dirChan:
+			if !ok {
+				dirChan = nil
+				continue
+			}
+			paths = append(paths, dir.Path)
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			assert.NoError(t, err)
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		filepath.Join(tmpDir, "project1", "node_modules"),
+		filepath.Join(tmpDir, "project2", "node_modules"),
+	}, paths)
+
+	dirChan, errChan = StreamDirStat(filepath.Join(tmpDir, "does-not-exist"))
+	_, ok := <-dirChan
+	assert.False(t, ok)
+	assert.ErrorIs(t, <-errChan, os.ErrNotExist)
+}
+
+func TestDirStat(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-dir-stat-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	for _, dir := range []string{"src", ".git"} {
+		err = os.MkdirAll(filepath.Join(tmpDir, dir), 0755)
+		assert.NoError(t, err)
+	}
+	for _, file := range []string{"src/main.go", ".git/HEAD"} {
+		err = os.WriteFile(filepath.Join(tmpDir, file), []byte("test"), 0644)
+		assert.NoError(t, err)
+	}
+
+	dir, err := DirStat(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, tmpDir, dir.Path)
+	assert.Equal(t, int64(8), dir.Size)
+	assert.Equal(t, 2, dir.NumberOfFiles)
+	assert.Equal(t, 3, dir.NumberOfSubdirs)
+
+	dir, err = DirStat(tmpDir, WithExclude(".git"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), dir.Size)
+	assert.Equal(t, 1, dir.NumberOfFiles)
+
+	_, err = DirStat(filepath.Join(tmpDir, "src", "main.go"))
+	assert.Error(t, err)
+}
+
+func TestDirStatWithDedupeHardlinks(t *testing.T) {
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"a/file": {Data: []byte("test")},
+		"a/link": fixtures.Hardlink("a/file"),
+		"b/link": fixtures.Hardlink("a/file"),
+	})
+
+	dir, err := DirStat(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), dir.Size)
+	assert.Equal(t, 3, dir.NumberOfFiles)
+
+	dir, err = DirStat(tmpDir, WithDedupeHardlinks())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), dir.Size)
+	assert.Equal(t, 1, dir.NumberOfFiles)
+}
+
+func TestDirStatSizeOnDisk(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-dir-stat-size-on-disk-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	err = os.MkdirAll(filepath.Join(tmpDir, "src"), 0755)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tmpDir, "src", "main.go"), make([]byte, 10000), 0644)
+	assert.NoError(t, err)
+
+	dir, err := DirStat(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10000), dir.Size)
+
+	expected, err := DiskUsage(tmpDir, AllocatedSize)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, dir.SizeOnDisk)
+}
+
+func TestStreamDirStatWithProgress(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-stream-dir-stat-with-progress-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	nodeModules := filepath.Join(tmpDir, "project1", "node_modules")
+	err = os.MkdirAll(nodeModules, 0755)
+	assert.NoError(t, err)
+	for i := 0; i < 600; i++ {
+		err = os.WriteFile(filepath.Join(nodeModules, fmt.Sprintf("file%d.js", i)), []byte("test"), 0644)
+		assert.NoError(t, err)
+	}
+
+	dirChan, errChan := StreamDirStat(tmpDir, WithKeywords("node_modules"), WithProgress(time.Nanosecond))
+
+	var results []DirectoryInfo
+	for dirChan != nil || errChan != nil {
+		select {
+		case dir, ok := <-dirChan:
+			if !ok {
+				dirChan = nil
+				continue
+			}
+			results = append(results, dir)
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			assert.NoError(t, err)
+		}
+	}
+
+	assert.Len(t, results, 3)
+	if len(results) != 3 {
+		return
+	}
+	assert.True(t, results[0].Partial)
+	assert.Equal(t, int64(256*4), results[0].Size)
+	assert.True(t, results[1].Partial)
+	assert.Equal(t, int64(512*4), results[1].Size)
+	assert.False(t, results[2].Partial)
+	assert.Equal(t, int64(600*4), results[2].Size)
+
+	directories, err := ListDirStat(tmpDir, WithKeywords("node_modules"), WithProgress(time.Nanosecond))
+	assert.NoError(t, err)
+	assert.Len(t, directories, 1)
+}
+
+func TestShallowDirStat(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-shallow-dir-stat-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	project1 := filepath.Join(tmpDir, "project1")
+	project2 := filepath.Join(tmpDir, "project2")
+	nested := filepath.Join(project1, "src", "node_modules")
+
+	err = os.MkdirAll(nested, 0755)
+	assert.NoError(t, err)
+	err = os.MkdirAll(project2, 0755)
+	assert.NoError(t, err)
+
+	// Files at every level to check the recursive sizes
+	err = os.WriteFile(filepath.Join(nested, "test.txt"), []byte("test content"), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(project2, "test.txt"), []byte("test"), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tmpDir, "root.txt"), []byte("ignored"), 0644)
+	assert.NoError(t, err)
+
+	directories, err := ShallowDirStat(tmpDir)
+	assert.NoError(t, err)
+	assert.Len(t, directories, 2)
+
+	for _, dir := range directories {
+		switch dir.Path {
+		case project1:
+			assert.Equal(t, int64(12), dir.Size)
+			assert.Equal(t, 1, dir.NumberOfFiles)
+		case project2:
+			assert.Equal(t, int64(4), dir.Size)
+			assert.Equal(t, 1, dir.NumberOfFiles)
+		default:
+			t.Fatalf("Unexpected directory path: %s", dir.Path)
+		}
+	}
+}
+
+func TestDirSize(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-dir-size-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	nested := filepath.Join(tmpDir, "project1", "node_modules")
+	err = os.MkdirAll(nested, 0755)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(nested, "test.txt"), []byte("test content"), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tmpDir, "root.txt"), []byte("test"), 0644)
+	assert.NoError(t, err)
+
+	size, err := DirSize(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(16), size)
+
+	_, err = DirSize(filepath.Join(tmpDir, "does-not-exist"))
+	assert.Error(t, err)
+}
+
+func TestDirSizeWithSymlinks(t *testing.T) {
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"root.txt":        fixtures.File(10),
+		"link.txt":        fixtures.Symlink("root.txt"),
+		"a/test.txt":      fixtures.File(100),
+		"a/link.txt":      fixtures.Symlink("test.txt"),
+		"a/b/nested.link": fixtures.Symlink("../test.txt"),
+	})
+
+	// Links are skipped wherever they are
+	size, err := DirSize(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(110), size)
+}
+
+func TestDirSizeWithUnreadableDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for the superuser")
+	}
+
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"root.txt":   fixtures.File(10),
+		"a/test.txt": fixtures.File(100),
+		"b/test.txt": fixtures.File(1000),
+		"b/private/": {Mode: 0200},
+	})
+
+	// The size of what could be read is kept alongside the error
+	size, err := DirSize(tmpDir)
+	assert.ErrorIs(t, err, os.ErrPermission)
+	var errs ErrorList
+	assert.ErrorAs(t, err, &errs)
+	assert.Equal(t, int64(1110), size)
+}
+
+func TestCountFiles(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-count-files-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	nested := filepath.Join(tmpDir, "project1", "src")
+	err = os.MkdirAll(nested, 0755)
+	assert.NoError(t, err)
+
+	for _, file := range []string{
+		filepath.Join(tmpDir, "README.md"),
+		filepath.Join(tmpDir, "project1", "main.go"),
+		filepath.Join(nested, "walk.go"),
+		filepath.Join(nested, "walk_test.go"),
+	} {
+		err = os.WriteFile(file, []byte("test"), 0644)
+		assert.NoError(t, err)
+	}
+
+	count, err := CountFiles(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	count, err = CountFiles(tmpDir, "*.go")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	count, err = CountFiles(tmpDir, "*_test.go", "*.md")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestCountFilesWithSymlinksAndErrors(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links requires privileges on Windows")
+	}
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for the superuser")
+	}
+
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"src/main.go":    fixtures.File(4),
+		"src/link.go":    fixtures.Symlink("main.go"),
+		"src/vendor":     fixtures.Symlink("."),
+		"docs/README.md": fixtures.File(4),
+		"private/":       {Mode: 0200},
+	})
+
+	// Symbolic links are not counted, and the files that could be read are
+	// counted alongside the errors
+	count, err := CountFiles(tmpDir)
+	assert.ErrorIs(t, err, os.ErrPermission)
+	assert.Equal(t, 2, count)
+
+	count, err = CountFiles(tmpDir, "*.go")
+	assert.ErrorIs(t, err, os.ErrPermission)
+	assert.Equal(t, 1, count)
+
+	_, err = CountFiles(filepath.Join(tmpDir, "src", "main.go"))
+	assert.Error(t, err)
+}
+
+func TestDirectoryInfoJSON(t *testing.T) {
+	dir := DirectoryInfo{
+		Path:            "/home/user/project/node_modules",
+		Size:            12,
+		SizeOnDisk:      8192,
+		CreationTime:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		LastModified:    time.Date(2024, 6, 7, 8, 9, 10, 0, time.UTC),
+		NumberOfFiles:   1,
+		NumberOfSubdirs: 2,
+		ComputedAt:      time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC),
+
+		NewestFileModTime: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		OldestFileModTime: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+	}
+
+	data, err := json.Marshal(dir)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"path": "/home/user/project/node_modules",
+		"size": 12,
+		"size_on_disk": 8192,
+		"creation_time": "2024-01-02T03:04:05Z",
+		"last_modified": "2024-06-07T08:09:10Z",
+		"number_of_files": 1,
+		"number_of_subdirs": 2,
+		"computed_at": "2024-06-08T00:00:00Z",
+		"newest_file_mod_time": "2024-06-01T00:00:00Z",
+		"oldest_file_mod_time": "2024-01-03T00:00:00Z"
+	}`, string(data))
+
+	var decoded DirectoryInfo
+	err = json.Unmarshal(data, &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, dir, decoded)
+}
+
+func BenchmarkListDirStatWorkers(b *testing.B) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "bench-list-dir-stat-workers-*")
+	assert.NoError(b, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(b, err)
+	}(tmpDir)
+
+	for i := 0; i < 50; i++ {
+		dir := filepath.Join(tmpDir, fmt.Sprintf("project%d", i), "node_modules")
+		err = os.MkdirAll(dir, 0755)
+		assert.NoError(b, err)
+		for j := 0; j < 20; j++ {
+			err = os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d", j)), []byte("test"), 0644)
+			assert.NoError(b, err)
+		}
+	}
+
+	for _, workers := range []int{1, 2, 4, 8, 16, 32, 64} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, err := ListDirStat(tmpDir, WithKeywords("node_modules"), WithWorkers(workers))
+				assert.NoError(b, err)
+			}
+		})
+	}
+}
+
+func TestDirStatFileModTimes(t *testing.T) {
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"project/old.txt":     fixtures.File(4).Aged(90 * 24 * time.Hour),
+		"project/src/new.txt": fixtures.File(4).Aged(10 * 24 * time.Hour),
+		"project/empty/":      fixtures.Dir(),
+	})
+
+	dir, err := DirStat(filepath.Join(tmpDir, "project"))
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(-10*24*time.Hour), dir.NewestFileModTime, time.Minute)
+	assert.WithinDuration(t, time.Now().Add(-90*24*time.Hour), dir.OldestFileModTime, time.Minute)
+	// Creating the directories touched them, hiding how old the files are.
+	assert.WithinDuration(t, time.Now(), dir.LastModified, time.Minute)
+
+	dir, err = DirStat(filepath.Join(tmpDir, "project", "empty"))
+	assert.NoError(t, err)
+	assert.True(t, dir.NewestFileModTime.IsZero())
+	assert.True(t, dir.OldestFileModTime.IsZero())
+}
+
+func TestDirStatWithFileSizeStats(t *testing.T) {
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"small.txt":      fixtures.File(10),
+		"src/big.bin":    fixtures.File(100),
+		"src/medium.bin": fixtures.File(40),
+		"empty/":         fixtures.Dir(),
+	})
+
+	dir, err := DirStat(tmpDir)
+	assert.NoError(t, err)
+	assert.Nil(t, dir.LargestFile)
+	assert.Zero(t, dir.AverageFileSize)
+
+	dir, err = DirStat(tmpDir, WithFileSizeStats())
+	assert.NoError(t, err)
+	if assert.NotNil(t, dir.LargestFile) {
+		assert.Equal(t, filepath.Join(tmpDir, "src", "big.bin"), dir.LargestFile.Path)
+		assert.Equal(t, int64(100), dir.LargestFile.Size)
+		assert.Equal(t, ".bin", dir.LargestFile.Extension)
+	}
+	assert.Equal(t, int64(50), dir.AverageFileSize)
+
+	dir, err = DirStat(filepath.Join(tmpDir, "empty"), WithFileSizeStats())
+	assert.NoError(t, err)
+	assert.Nil(t, dir.LargestFile)
+	assert.Zero(t, dir.AverageFileSize)
+}
@@ -0,0 +1,343 @@
+package go_walk
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ChangeKind describes how a directory changed between two polls.
+type ChangeKind int
+
+const (
+	// Added is a directory that appeared.
+	Added ChangeKind = iota
+	// Modified is a directory whose size, file count or modification time
+	// changed.
+	Modified
+	// Removed is a directory that disappeared.
+	Removed
+)
+
+// String returns the name of the kind.
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Modified:
+		return "modified"
+	default:
+		return "removed"
+	}
+}
+
+// Change reports a directory that changed between two polls.
+type Change struct {
+	Kind      ChangeKind
+	Directory DirectoryInfo // Current stats of the directory, or its last known stats if it was removed.
+	Previous  DirectoryInfo // Stats of the directory when it was last reported, zero if it was added.
+
+	// SizeDelta and FilesDelta are how many bytes and files the directory
+	// gained since it was last reported, negative if it lost them, so that
+	// live views can be kept up to date without comparing the stats.
+	SizeDelta  int64
+	FilesDelta int
+}
+
+// withDeltas returns c with its deltas computed from its stats.
+func (c Change) withDeltas() Change {
+	switch c.Kind {
+	case Added:
+		c.SizeDelta, c.FilesDelta = c.Directory.Size, c.Directory.NumberOfFiles
+	case Removed:
+		c.SizeDelta, c.FilesDelta = -c.Previous.Size, -c.Previous.NumberOfFiles
+	default:
+		c.SizeDelta = c.Directory.Size - c.Previous.Size
+		c.FilesDelta = c.Directory.NumberOfFiles - c.Previous.NumberOfFiles
+	}
+	return c
+}
+
+// coalesce returns the change combining c with an earlier change, not yet
+// delivered, of the same directory, or false if together they cancel out,
+// such as a directory added and removed again.
+func coalesce(earlier, c Change) (Change, bool) {
+	switch {
+	case earlier.Kind == Added && c.Kind == Removed:
+		return Change{}, false
+	case earlier.Kind == Added:
+		c.Kind, c.Previous = Added, DirectoryInfo{}
+	case earlier.Kind == Removed:
+		// The directory came back.
+		c.Kind, c.Previous = Modified, earlier.Previous
+	default:
+		c.Previous = earlier.Previous
+	}
+	if c.Kind == Modified && !changed(c.Previous, c.Directory) {
+		return Change{}, false
+	}
+	return c.withDeltas(), true
+}
+
+// changeQueue holds the changes not yet delivered, one per directory, in the
+// order the directories first changed.
+type changeQueue struct {
+	debounce    time.Duration // How long a directory must stop changing before it is delivered.
+	minInterval time.Duration // Least time between two deliveries of a directory.
+
+	pending   map[string]Change
+	order     []string
+	changedAt map[string]time.Time // When each pending directory last changed.
+	sentAt    map[string]time.Time // When directories were last delivered, within minInterval.
+}
+
+// add queues c, seen at now, coalescing it with a change of the same
+// directory already queued.
+func (q *changeQueue) add(c Change, now time.Time) {
+	path := c.Directory.Path
+	earlier, exists := q.pending[path]
+	if !exists {
+		if q.pending == nil {
+			q.pending = make(map[string]Change)
+			q.changedAt = make(map[string]time.Time)
+		}
+		q.pending[path] = c
+		q.changedAt[path] = now
+		q.order = append(q.order, path)
+		return
+	}
+
+	if c, ok := coalesce(earlier, c); ok {
+		q.pending[path] = c
+		q.changedAt[path] = now
+	} else {
+		q.remove(path)
+	}
+}
+
+// next returns the change to deliver next at now, or false if none is due
+// yet.
+func (q *changeQueue) next(now time.Time) (Change, bool) {
+	for _, path := range q.order {
+		if now.Sub(q.changedAt[path]) < q.debounce {
+			continue
+		}
+		if sent, exists := q.sentAt[path]; exists && now.Sub(sent) < q.minInterval {
+			continue
+		}
+		return q.pending[path], true
+	}
+	return Change{}, false
+}
+
+// sent drops the change of the directory at path, delivered at now.
+func (q *changeQueue) sent(path string, now time.Time) {
+	q.remove(path)
+	if q.minInterval <= 0 {
+		return
+	}
+
+	if q.sentAt == nil {
+		q.sentAt = make(map[string]time.Time)
+	}
+	q.sentAt[path] = now
+	// Deliveries that no longer hold anything back are forgotten.
+	for p, sent := range q.sentAt {
+		if now.Sub(sent) >= q.minInterval {
+			delete(q.sentAt, p)
+		}
+	}
+}
+
+// remove drops the change of the directory at path.
+func (q *changeQueue) remove(path string) {
+	delete(q.pending, path)
+	delete(q.changedAt, path)
+	q.order = slices.DeleteFunc(q.order, func(p string) bool { return p == path })
+}
+
+// PollWatcher watches a directory tree by repeatedly scanning it and comparing
+// the results. It works on any filesystem, including network and FUSE
+// filesystems that do not deliver change notifications. Polling carries on
+// while the receiver is busy: the changes of a directory that pile up in the
+// meantime are coalesced into one, spanning from the stats last delivered to
+// the latest ones, and those that cancel out are dropped. WithDebounce and
+// WithChangeInterval hold changes back further, for directories that change
+// in bursts or all the time.
+type PollWatcher struct {
+	root           string
+	opts           []Option
+	interval       time.Duration
+	debounce       time.Duration // Set by WithDebounce.
+	changeInterval time.Duration // Set by WithChangeInterval.
+
+	ctx     context.Context    // Stops a scan in progress once the watcher is closed.
+	cancel  context.CancelFunc // Cancels ctx.
+	changes chan Change
+	errors  chan error
+	done    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+}
+
+// NewPollWatcher scans root for the directories selected by opts, like
+// ListDirStat, and then scans it again every interval, reporting the
+// directories that changed. Parts of the tree that cannot be read do not stop
+// it: what could be scanned is compared, and the errors are delivered on
+// Errors, where the errors of a scan not yet received when the next one
+// finishes are replaced by the newer ones. The receiver must read from both
+// Changes and Errors until Close is called.
+func NewPollWatcher(root string, interval time.Duration, opts ...Option) (*PollWatcher, error) {
+	if interval <= 0 {
+		return nil, errors.New("the poll interval must be positive")
+	}
+
+	o := newOptions(opts...)
+	w := &PollWatcher{
+		root:           root,
+		opts:           opts,
+		interval:       interval,
+		debounce:       o.debounce,
+		changeInterval: o.changeInterval,
+		changes:        make(chan Change),
+		errors:         make(chan error),
+		done:           make(chan struct{}),
+	}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+
+	directories, err := w.scan()
+	var partial ErrorList
+	if err != nil && !errors.As(err, &partial) {
+		w.cancel()
+		return nil, err
+	}
+
+	w.wg.Add(1)
+	go w.run(indexByPath(directories), err)
+
+	return w, nil
+}
+
+// scan scans the tree once, stopping early if the watcher is closed.
+func (w *PollWatcher) scan() ([]DirectoryInfo, error) {
+	return ListDirStatContext(w.ctx, w.root, w.opts...)
+}
+
+// Changes returns the channel on which changed directories are delivered.
+func (w *PollWatcher) Changes() <-chan Change {
+	return w.changes
+}
+
+// Errors returns the channel on which scan errors are delivered.
+func (w *PollWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Close stops the watcher, including a scan in progress, and closes its
+// channels.
+func (w *PollWatcher) Close() {
+	w.once.Do(func() {
+		w.cancel()
+		close(w.done)
+		w.wg.Wait()
+		close(w.changes)
+		close(w.errors)
+	})
+}
+
+// run polls the tree until the watcher is closed, starting from the stats in
+// previous, which were scanned with the errors in err.
+func (w *PollWatcher) run(previous map[string]DirectoryInfo, err error) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	queue := changeQueue{debounce: w.debounce, minInterval: w.changeInterval}
+	for {
+		// Only offer what there is to deliver. Changes held back are
+		// looked at again after the next poll.
+		var changes chan<- Change
+		next, ok := queue.next(time.Now())
+		if ok {
+			changes = w.changes
+		}
+		var errs chan<- error
+		if err != nil {
+			errs = w.errors
+		}
+
+		select {
+		case <-w.done:
+			return
+		case changes <- next:
+			queue.sent(next.Directory.Path, time.Now())
+			continue
+		case errs <- err:
+			err = nil
+			continue
+		case <-ticker.C:
+		}
+
+		directories, scanErr := w.scan()
+		if w.ctx.Err() != nil {
+			return
+		}
+		if scanErr != nil {
+			err = scanErr
+		}
+		var partial ErrorList
+		if scanErr != nil && !errors.As(scanErr, &partial) {
+			// The root could not be scanned at all, which says nothing
+			// about the directories below it.
+			continue
+		}
+
+		current := indexByPath(directories)
+		now := time.Now()
+		for _, change := range diffDirectories(previous, current) {
+			queue.add(change, now)
+		}
+		previous = current
+	}
+}
+
+// indexByPath returns directories keyed by their path.
+func indexByPath(directories []DirectoryInfo) map[string]DirectoryInfo {
+	index := make(map[string]DirectoryInfo, len(directories))
+	for _, dir := range directories {
+		index[dir.Path] = dir
+	}
+	return index
+}
+
+// diffDirectories returns the changes that turn previous into current.
+func diffDirectories(previous, current map[string]DirectoryInfo) []Change {
+	var changes []Change
+	for path, dir := range current {
+		old, exists := previous[path]
+		switch {
+		case !exists:
+			changes = append(changes, Change{Kind: Added, Directory: dir})
+		case changed(old, dir):
+			changes = append(changes, Change{Kind: Modified, Directory: dir, Previous: old})
+		}
+	}
+	for path, old := range previous {
+		if _, exists := current[path]; !exists {
+			changes = append(changes, Change{Kind: Removed, Directory: old, Previous: old})
+		}
+	}
+	for i := range changes {
+		changes[i] = changes[i].withDeltas()
+	}
+	return changes
+}
+
+// changed reports whether a directory whose stats were old has changed to
+// dir.
+func changed(old, dir DirectoryInfo) bool {
+	return old.Size != dir.Size || old.NumberOfFiles != dir.NumberOfFiles || !old.LastModified.Equal(dir.LastModified)
+}
@@ -0,0 +1,309 @@
+package go_walk
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/akshaybabloo/go-walk/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollWatcher(t *testing.T) {
+	// Create a temporary directory structure
+	tmpDir, err := os.MkdirTemp("", "test-poll-watcher-*")
+	assert.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		assert.NoError(t, err)
+	}(tmpDir)
+
+	nodeModules := filepath.Join(tmpDir, "project1", "node_modules")
+
+	w, err := NewPollWatcher(tmpDir, 10*time.Millisecond, WithKeywords("node_modules"))
+	assert.NoError(t, err)
+	defer w.Close()
+
+	// Errors from directories disappearing mid-scan are expected
+	next := func() Change {
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case change := <-w.Changes():
+				return change
+			case <-w.Errors():
+			case <-timeout:
+				t.Fatal("Timed out waiting for a change")
+				return Change{}
+			}
+		}
+	}
+
+	err = os.MkdirAll(nodeModules, 0755)
+	assert.NoError(t, err)
+	change := next()
+	assert.Equal(t, Added, change.Kind)
+	assert.Equal(t, nodeModules, change.Directory.Path)
+
+	err = os.WriteFile(filepath.Join(nodeModules, "test.txt"), []byte("test content"), 0644)
+	assert.NoError(t, err)
+	change = next()
+	assert.Equal(t, Modified, change.Kind)
+	assert.Equal(t, int64(12), change.Directory.Size)
+	assert.Equal(t, int64(0), change.Previous.Size)
+	assert.Equal(t, int64(12), change.SizeDelta)
+	assert.Equal(t, 1, change.FilesDelta)
+
+	err = os.RemoveAll(nodeModules)
+	assert.NoError(t, err)
+	change = next()
+	assert.Equal(t, Removed, change.Kind)
+	assert.Equal(t, nodeModules, change.Directory.Path)
+	assert.Equal(t, int64(-12), change.SizeDelta)
+}
+
+func TestPollWatcherCoalescesChanges(t *testing.T) {
+	tmpDir := fixtures.Build(t, fixtures.Tree{})
+	nodeModules := filepath.Join(tmpDir, "project1", "node_modules")
+
+	w, err := NewPollWatcher(tmpDir, 5*time.Millisecond, WithKeywords("node_modules"))
+	assert.NoError(t, err)
+	defer w.Close()
+
+	// Nothing is received while the directory is added and filled over
+	// several polls.
+	err = os.MkdirAll(nodeModules, 0755)
+	assert.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	err = os.WriteFile(filepath.Join(nodeModules, "test.txt"), []byte("test content"), 0644)
+	assert.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case change := <-w.Changes():
+			if change.Directory.Size == 0 {
+				// The scan in progress when the directory was filled.
+				continue
+			}
+			assert.Equal(t, Added, change.Kind)
+			assert.Equal(t, int64(12), change.SizeDelta)
+			assert.Equal(t, 1, change.FilesDelta)
+			return
+		case <-w.Errors():
+		case <-timeout:
+			t.Fatal("Timed out waiting for a change")
+		}
+	}
+}
+
+func TestChangeQueue(t *testing.T) {
+	empty := DirectoryInfo{Path: "/a"}
+	small := DirectoryInfo{Path: "/a", Size: 10, NumberOfFiles: 1}
+	large := DirectoryInfo{Path: "/a", Size: 30, NumberOfFiles: 3}
+	other := DirectoryInfo{Path: "/b", Size: 5}
+
+	now := time.Now()
+	var queue changeQueue
+	queue.add(Change{Kind: Added, Directory: empty}.withDeltas(), now)
+	queue.add(Change{Kind: Added, Directory: other}.withDeltas(), now)
+	queue.add(Change{Kind: Modified, Directory: small, Previous: empty}.withDeltas(), now)
+	queue.add(Change{Kind: Modified, Directory: large, Previous: small}.withDeltas(), now)
+
+	// Still added, with everything it gained, and first as it changed first
+	next, ok := queue.next(now)
+	assert.True(t, ok)
+	assert.Equal(t, Change{Kind: Added, Directory: large, SizeDelta: 30, FilesDelta: 3}, next)
+	queue.sent(next.Directory.Path, now)
+	next, _ = queue.next(now)
+	assert.Equal(t, Change{Kind: Added, Directory: other, SizeDelta: 5}, next)
+	queue.sent(next.Directory.Path, now)
+
+	queue.add(Change{Kind: Removed, Directory: other, Previous: other}.withDeltas(), now)
+	queue.add(Change{Kind: Added, Directory: other}.withDeltas(), now)
+	queue.add(Change{Kind: Modified, Directory: large, Previous: small}.withDeltas(), now)
+	queue.add(Change{Kind: Modified, Directory: small, Previous: large}.withDeltas(), now)
+	_, ok = queue.next(now)
+	assert.False(t, ok, "changes that cancel out are dropped")
+
+	queue.add(Change{Kind: Modified, Directory: small, Previous: large}.withDeltas(), now)
+	queue.add(Change{Kind: Removed, Directory: small, Previous: small}.withDeltas(), now)
+	next, ok = queue.next(now)
+	assert.True(t, ok)
+	assert.Equal(t, Change{Kind: Removed, Directory: small, Previous: large, SizeDelta: -30, FilesDelta: -3}, next)
+}
+
+func TestPollWatcherWithOptions(t *testing.T) {
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"project1/node_modules/index.js": fixtures.File(4),
+	})
+	vendor := filepath.Join(tmpDir, "vendor", "node_modules")
+
+	w, err := NewPollWatcher(tmpDir, 10*time.Millisecond, WithKeywords("node_modules"), WithExclude("vendor"))
+	assert.NoError(t, err)
+	defer w.Close()
+
+	err = os.MkdirAll(vendor, 0755)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tmpDir, "project1", "node_modules", "lib.js"), []byte("test"), 0644)
+	assert.NoError(t, err)
+
+	// The excluded directory never shows up
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case change := <-w.Changes():
+			assert.Equal(t, filepath.Join(tmpDir, "project1", "node_modules"), change.Directory.Path)
+			return
+		case <-w.Errors():
+		case <-timeout:
+			t.Fatal("Timed out waiting for a change")
+		}
+	}
+}
+
+func TestPollWatcherInvalidInterval(t *testing.T) {
+	_, err := NewPollWatcher(os.TempDir(), 0, WithKeywords("node_modules"))
+	assert.Error(t, err)
+}
+
+func TestPollWatcherMissingRoot(t *testing.T) {
+	_, err := NewPollWatcher(filepath.Join(os.TempDir(), "test-poll-watcher-missing"), time.Second)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestPollWatcherWithUnreadableDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for the superuser")
+	}
+
+	tmpDir := fixtures.Build(t, fixtures.Tree{
+		"private/": {Mode: 0200},
+	})
+	nodeModules := filepath.Join(tmpDir, "project1", "node_modules")
+
+	w, err := NewPollWatcher(tmpDir, 10*time.Millisecond, WithKeywords("node_modules"))
+	assert.NoError(t, err)
+	defer w.Close()
+
+	// The unreadable directory is reported, without keeping changes
+	// elsewhere from being delivered.
+	timeout := time.After(5 * time.Second)
+	select {
+	case err := <-w.Errors():
+		assert.ErrorIs(t, err, os.ErrPermission)
+	case <-timeout:
+		t.Fatal("Timed out waiting for an error")
+	}
+
+	err = os.MkdirAll(nodeModules, 0755)
+	assert.NoError(t, err)
+	for {
+		select {
+		case change := <-w.Changes():
+			assert.Equal(t, Added, change.Kind)
+			assert.Equal(t, nodeModules, change.Directory.Path)
+			return
+		case <-w.Errors():
+		case <-timeout:
+			t.Fatal("Timed out waiting for a change")
+		}
+	}
+}
+
+func TestPollWatcherCloseStopsScan(t *testing.T) {
+	tree := fixtures.Tree{}
+	for i := 0; i < 200; i++ {
+		tree[fmt.Sprintf("project%d/node_modules/a/b/c/index.js", i)] = fixtures.File(4)
+	}
+	tmpDir := fixtures.Build(t, tree)
+
+	w, err := NewPollWatcher(tmpDir, time.Millisecond, WithKeywords("node_modules"))
+	assert.NoError(t, err)
+	go func() {
+		for range w.Errors() {
+		}
+	}()
+
+	// Let a scan start, then close while it is running.
+	time.Sleep(5 * time.Millisecond)
+	closed := make(chan struct{})
+	go func() {
+		w.Close()
+		close(closed)
+	}()
+	for {
+		select {
+		case <-w.Changes():
+		case <-closed:
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatal("Close did not return")
+		}
+	}
+}
+
+func TestChangeQueueHoldsBack(t *testing.T) {
+	small := DirectoryInfo{Path: "/a", Size: 10}
+	large := DirectoryInfo{Path: "/a", Size: 30}
+	now := time.Now()
+
+	// Debounced changes wait until the directory has been quiet long enough
+	queue := changeQueue{debounce: time.Minute}
+	queue.add(Change{Kind: Added, Directory: small}.withDeltas(), now)
+	queue.add(Change{Kind: Modified, Directory: large, Previous: small}.withDeltas(), now.Add(30*time.Second))
+	_, ok := queue.next(now.Add(time.Minute))
+	assert.False(t, ok)
+	next, ok := queue.next(now.Add(90 * time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, int64(30), next.SizeDelta)
+
+	// Directories are delivered at most once a minute
+	queue = changeQueue{minInterval: time.Minute}
+	queue.add(Change{Kind: Added, Directory: small}.withDeltas(), now)
+	next, _ = queue.next(now)
+	queue.sent(next.Directory.Path, now)
+	queue.add(Change{Kind: Modified, Directory: large, Previous: small}.withDeltas(), now.Add(time.Second))
+	_, ok = queue.next(now.Add(time.Second))
+	assert.False(t, ok)
+	_, ok = queue.next(now.Add(time.Minute))
+	assert.True(t, ok)
+	queue.sent("/a", now.Add(time.Minute))
+	assert.Len(t, queue.sentAt, 1)
+	queue.sent("/b", now.Add(3*time.Minute))
+	assert.Len(t, queue.sentAt, 1, "deliveries no longer holding anything back are forgotten")
+}
+
+func TestPollWatcherWithDebounce(t *testing.T) {
+	tmpDir := fixtures.Build(t, fixtures.Tree{})
+	nodeModules := filepath.Join(tmpDir, "project1", "node_modules")
+
+	w, err := NewPollWatcher(tmpDir, 5*time.Millisecond, WithKeywords("node_modules"), WithDebounce(100*time.Millisecond))
+	assert.NoError(t, err)
+	defer w.Close()
+
+	// Filled in a burst of writes, the directory is reported once
+	err = os.MkdirAll(nodeModules, 0755)
+	assert.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		err = os.WriteFile(filepath.Join(nodeModules, fmt.Sprintf("%d.txt", i)), []byte("test"), 0644)
+		assert.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case change := <-w.Changes():
+			assert.Equal(t, Added, change.Kind)
+			assert.Equal(t, 5, change.FilesDelta)
+			return
+		case <-w.Errors():
+		case <-timeout:
+			t.Fatal("Timed out waiting for a change")
+		}
+	}
+}